@@ -0,0 +1,141 @@
+package pogo
+
+import (
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo/postgres13"
+)
+
+// autovacuumSettingNames13 are the names of the settings in pg_settings read
+// into postgres13.AutovacuumSettings.
+var autovacuumSettingNames13 = []string{
+	"autovacuum",
+	"track_counts",
+	"autovacuum_naptime",
+	"autovacuum_max_workers",
+	"autovacuum_vacuum_threshold",
+	"autovacuum_vacuum_scale_factor",
+	"autovacuum_vacuum_insert_threshold",
+	"autovacuum_vacuum_insert_scale_factor",
+	"autovacuum_analyze_threshold",
+	"autovacuum_analyze_scale_factor",
+}
+
+// AutovacuumHealth13 reads the autovacuum settings from pg_settings, the user
+// tables with the thresholds autovacuum applies to them given their storage
+// parameters, and the autovacuum workers running in pg_stat_activity.
+// The tables come with the most dead rows first. Their methods tell how many
+// rows are left to be modified before they're auto-vacuumed or auto-analyzed,
+// and which ones are overdue. Postgres doesn't record the rate the rows are
+// modified at, so when that happens is left to compare readings over time.
+// Overdue tables are processed on the next visit of their database, every
+// autovacuum_naptime, if a worker is free.
+// It is meant to be used for Postgres v13.
+func (qr QueryRunner) AutovacuumHealth13() (postgres13.AutovacuumHealth, error) {
+	if v := GetPostgresVersion(); v != Postgres13 {
+		return postgres13.AutovacuumHealth{}, getVersionMismatchError(Postgres13, v)
+	}
+
+	settings, err := qr.autovacuumSettings13()
+	if err != nil {
+		return postgres13.AutovacuumHealth{}, err
+	}
+	tables, err := qr.autovacuumTables13(settings)
+	if err != nil {
+		return postgres13.AutovacuumHealth{}, err
+	}
+	workers, err := qr.autovacuumWorkers13()
+	if err != nil {
+		return postgres13.AutovacuumHealth{}, err
+	}
+
+	return postgres13.AutovacuumHealth{
+		Settings: settings,
+		Tables:   tables,
+		Workers:  workers,
+	}, nil
+}
+
+func (qr QueryRunner) autovacuumSettings13() (postgres13.AutovacuumSettings, error) {
+	q := `SELECT name, setting FROM pg_settings WHERE name = ANY($1)`
+	rows, err := qr.query(nil, q, pq.StringArray(autovacuumSettingNames13))
+	if err != nil {
+		return postgres13.AutovacuumSettings{}, errors.Wrap(err, "querying pg_settings")
+	}
+	defer rows.Close()
+
+	var s postgres13.AutovacuumSettings
+	for rows.Next() {
+		var name, setting string
+		if err := rows.Scan(&name, &setting); err != nil {
+			return postgres13.AutovacuumSettings{}, errors.Wrap(err, "scanning pg_settings row")
+		}
+		if err := s.Set(name, setting); err != nil {
+			return postgres13.AutovacuumSettings{}, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return postgres13.AutovacuumSettings{}, errors.Wrap(err, "reading pg_settings rows")
+	}
+
+	return s, nil
+}
+
+func (qr QueryRunner) autovacuumTables13(settings postgres13.AutovacuumSettings) ([]postgres13.TableAutovacuum, error) {
+	// TableAutovacuum reads the columns of pg_class along with the statistics.
+	queryable := StatUserTablesView.OrderBy(Desc("n_dead_tup"))
+	queryable.Specifier = &postgres13.TableAutovacuum{}
+
+	rows, err := qr.Rows(queryable)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying pg_stat_user_tables")
+	}
+	defer rows.Close()
+
+	ts := make([]postgres13.TableAutovacuum, 0)
+	for rows.Next() {
+		var t postgres13.TableAutovacuum
+		dest := queryable.ScanDestinations(&t)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scanning pg_stat_user_tables row")
+		}
+		if t.Settings, err = settings.ForTable(t.RelOptions); err != nil {
+			return nil, errors.Wrapf(err, "reading storage parameters of %s", t.RelName.String)
+		}
+
+		ts = append(ts, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading pg_stat_user_tables rows")
+	}
+
+	return ts, nil
+}
+
+func (qr QueryRunner) autovacuumWorkers13() (postgres13.StatActivities, error) {
+	queryable := StatActivityView.Where("backend_type = 'autovacuum worker'")
+
+	rows, err := qr.Rows(queryable)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying pg_stat_activity")
+	}
+	defer rows.Close()
+
+	ss := make(postgres13.StatActivities, 0)
+	for rows.Next() {
+		var s postgres13.StatActivityJoined
+		dest := queryable.ScanDestinations(&s)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scanning pg_stat_activity row")
+		}
+
+		ss = append(ss, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading pg_stat_activity rows")
+	}
+
+	return ss, nil
+}
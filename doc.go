@@ -25,18 +25,29 @@
 //   pg_stat_archiver
 //   pg_stat_bgwriter
 //   pg_stat_slru
+//   pg_stat_statements (with the pg_stat_statements extension)
+//   pg_stat_progress_vacuum
+//   pg_stat_progress_analyze
+//   pg_stat_progress_create_index
+//   pg_stat_progress_cluster
+//   pg_stat_progress_basebackup
+//   pg_replication_slots
+//   pg_prepared_xacts
+//   pg_stat_xact_user_tables
+//   pg_stat_xact_user_functions
 //
-// Among these relations, the following four relations are supported as "primary" query targets
+// Among these relations, the following five relations are supported as "primary" query targets
 // (i.e. pogo provides functions that query them directly and give you the
 // resulting rows as defined structs)
 //   pg_locks
 //   pg_stat_activity
 //   pg_stat_replication
 //   pg_stat_user_tables
+//   pg_stat_statements
 //
 // Examples:
 //
-// Querying relations other than the four primary supported targets
+// Querying relations other than the five primary supported targets
 // (querying pg_stat_database view joining with pg_stat_activity view and
 // pg_locks view, and pg_stat_activity view in turn includes pg_blocking_pids(pid)):
 //  rows, err := pogo.Query(sql.DB).For(
@@ -65,11 +76,15 @@
 //    - pg_stat_database (on pid)
 //    - pg_stat_database_conflicts (on pid)
 //    - pg_blocking_pids(pg_stat_activity.pid)
+//    - pg_safe_snapshot_blocking_pids(pg_stat_activity.pid)
+//    - age(pg_stat_activity.backend_xid)
+//    - age(pg_stat_activity.backend_xmin)
 //   pg_stat_replication
 //    - pg_locks (on pid)
 //    - pg_stat_ssl (on pid)
 //    - pg_stat_gssapi (on pid)
 //    - pg_stat_wal_receiver (on pid)
+//    - pg_wal_lsn_diff (or pg_xlog_location_diff) of replay_lsn
 //   pg_stat_user_tables
 //    - pg_locks (on relid)
 //    - pg_stat_user_indexes (on relid)
@@ -77,6 +92,7 @@
 //    - pg_statio_user_indexes (on relid)
 //    - pg_statio_user_sequences (on relid)
 //    - pg_statio_user_tables (on relid)
+//    - pg_total_relation_size, pg_relation_size, pg_indexes_size of relid
 //   pg_locks
 //    - pg_stat_activity (on pid)
 //    - pg_stat_database (on database)
@@ -101,12 +117,15 @@
 //    - pg_database_conflicts (on datid)
 //    - pg_locks (on datid)
 //    - pg_stat_activity (on datid)
+//    - pg_stat_statements (on dbid)
+//    - pg_database_size(pg_stat_database.datid), null for the shared objects
 //   pg_stat_database_conflicts
 //   pg_stat_user_indexes
 //    - pg_stat_user_tables (on relid)
 //    - pg_statio_user_tables (on relid)
 //    - pg_locks (on indexrelid)
 //    - pg_statio_user_indexes (on indexrelid)
+//    - pg_total_relation_size, pg_relation_size of indexrelid
 //   pg_statio_user_indexes
 //   pg_statio_user_sequences
 //   pg_statio_user_tables
@@ -115,8 +134,200 @@
 //   pg_stat_archiver
 //   pg_stat_bgwriter
 //   pg_stat_slru
+//   pg_stat_statements
+//    - pg_stat_database (on dbid)
+//   pg_stat_progress_{vacuum,analyze,create_index,cluster}
+//    - pg_stat_activity (on pid)
+//    - pg_stat_user_tables (on relid)
+//   pg_stat_progress_basebackup
+//    - pg_stat_activity (on pid)
+//   pg_replication_slots
+//    - pg_stat_activity (on active_pid)
+//    - pg_stat_replication (on active_pid)
+//   pg_prepared_xacts
+//    - pg_locks (on virtualtransaction, as '-1/' || transaction)
+//    - pg_locks (on transactionid)
+//   pg_stat_xact_user_tables
+//    - pg_stat_user_tables (on relid)
+//   pg_stat_xact_user_functions
+//
+// The pg_stat_xact_* views count what the current transaction has done so far.
+// TrackXactStats13 (or TrackXactStats9) runs a function in a transaction,
+// rolled back afterwards, and returns what it did, e.g. to assert in tests
+// that it did no sequential scans:
+//  stats, err := pogo.TrackXactStats13(ctx, sql.DB, func(tx *sql.Tx) error {
+//  	return updateBalance(tx)
+//  })
+//
+// The function queryables are selected as columns of their parent rows, into the
+// typed fields of the joined structs, e.g. how far behind each standby is
+// replaying, in bytes:
+//  replications, err := pogo.Query(sql.DB).StatReplication13("", pogo.ReplayLagBytes)
+//
+// The WAL each replication slot retains is computed in retained_bytes, which
+// can be ordered on like the other columns:
+//  rows, err := pogo.Query(sql.DB).For(
+//  	pogo.ReplicationSlotsView.
+//  		OrderBy(pogo.Desc("retained_bytes")).
+//  		With(pogo.StatReplicationView),
+//  )
+//
+// XminHolders returns what holds back the xmin horizon, and so keeps vacuum from
+// removing dead rows, oldest first: backends, replication slots, prepared
+// transactions and standbys with hot_standby_feedback:
+//  holders, err := pogo.Query(sql.DB).XminHolders()
+//
+// Wraparound reports the ages of the oldest transaction IDs and multixact IDs of
+// the databases and the tables, with the IDs left before autovacuum is forced to
+// freeze them and before the server shuts down to avoid wraparound:
+//  report, err := pogo.Query(sql.DB).Wraparound(20)
+//
+// AutovacuumHealth13 computes the autovacuum thresholds of the user tables from
+// pg_settings and their storage parameters, and lists the autovacuum workers
+// running, e.g. for the tables autovacuum is behind on:
+//  health, err := pogo.Query(sql.DB).AutovacuumHealth13()
+//  overdue := health.OverdueTables()
+//
+// pg_stat_statements can't be joined with pg_stat_activity, which has no
+// query_id before Postgres 14. StatStatements13 (or StatStatements9) and the
+// other queries on StatStatementsView fail with an error wrapping
+// ErrStatStatementsNotInstalled, along with the error of the driver, if the
+// extension isn't created in the database:
+//  statements, err := pogo.Query(sql.DB).
+//  	Options(pogo.OrderBy(pogo.Desc("total_exec_time")), pogo.Limit(10)).
+//  	StatStatements13("calls > 100", pogo.StatDatabaseView.Columns("datname"))
+//
+// The table, index and sequence statistics views read the user objects by
+// default. Their sys and all variants are read with Scope, with the same joins
+// and structs; pg_stat_user_functions has no such variant:
+//  rows, err := pogo.Query(sql.DB).For(
+//  	pogo.StatUserTablesView.Scope(pogo.AllTables).With(pogo.StatIOUserTablesView.Scope(pogo.AllTables)),
+//  )
 //
 // You can join recursively with any depth you want (as long as there are no
 // cycles), although high depth will incur performance hit. Use at your own risk.
 //
+// Rows can be ordered, limited and paginated with a cursor, at the top level as
+// well as per parent row in joins. For example, the 20 oldest transactions with
+// at most 10 of their locks each:
+//  rows, err := pogo.Query(sql.DB).For(
+//  	pogo.StatActivityView.
+//  		Where("xact_start IS NOT NULL").
+//  		OrderBy(pogo.Asc("xact_start")).
+//  		Limit(20).
+//  		With(pogo.LocksView.Limit(10)),
+//  )
+//
+// The convenience methods take the same options for their top-level relation:
+//  statTables, err := pogo.Query(sql.DB).
+//  	Options(pogo.OrderBy(pogo.Desc("n_dead_tup")), pogo.Limit(50)).
+//  	StatTable13("")
+//
+// By default, the parent rows are grouped by all of their columns to aggregate the
+// joined rows. For parents with wide columns, such as pg_stat_activity.query, the
+// joined rows can be aggregated in correlated lateral subqueries instead, which
+// yields the same result:
+//  rows, err := pogo.Query(sql.DB).For(
+//  	pogo.StatActivityView.JoinStrategy(pogo.JoinStrategyLateral).With(
+//  		pogo.LocksView,
+//  	),
+//  )
+//
+// Only some of the columns can be selected, at any level of the joins. Scan the
+// rows with Queryable.ScanDestinations, which binds the selected columns only:
+//  queryable := pogo.StatActivityView.
+//  	Columns("pid", "state", "wait_event", "xact_start").
+//  	With(pogo.LocksView.Columns("mode", "granted"))
+//  rows, err := pogo.Query(sql.DB).For(queryable)
+//  for rows.Next() {
+//  	var s postgres13.StatActivityJoined
+//  	err := rows.Scan(queryable.ScanDestinations(&s)...)
+//  }
+//
+// Joined rows can be aggregated into a scalar value instead of being selected as
+// they are, with Count, Exists, Sum or ArrayAgg. The value is scanned into the
+// Aggregate field of the join, e.g. LocksAggregate.Count for pg_locks joined
+// under pg_stat_activity. Use Queryable.ScanDestinations to bind it:
+//  queryable := pogo.StatActivityView.With(
+//  	pogo.LocksView.Where("NOT pg_locks.granted").Count(),
+//  )
+//
+// All the joins are left joins, so the parent rows are returned whether or not
+// they have joined rows. Mark a joined queryable Required to keep only the parent
+// rows having any joined rows matching its Where, e.g. the backends waiting on a
+// lock. It works at any depth of the joins:
+//  rows, err := pogo.Query(sql.DB).For(
+//  	pogo.StatActivityView.With(
+//  		pogo.LocksView.Where("NOT pg_locks.granted").Required(),
+//  	),
+//  )
+//
+// Calling the convenience methods one after another reads each view at a
+// different point in time. Snapshot13 (or Snapshot9) reads the given views in
+// a single query instead, so that their numbers line up:
+//  snapshot, err := pogo.Query(sql.DB).Snapshot13(
+//  	pogo.StatActivityView.With(pogo.LocksView),
+//  	pogo.LocksView,
+//  	pogo.StatUserTablesView,
+//  )
+//  fmt.Println(snapshot.CapturedAt, len(snapshot.StatActivities))
+//
+// Monitoring queries can be run in a Session, which runs each of them in a
+// read-only transaction of its own with statement, lock and idle timeouts set
+// locally, and leaves pogo's own backend out of pg_stat_activity and pg_locks:
+//  session := pogo.NewSession(sql.DB)
+//  statActivities, err := pogo.QueryWith(session).StatActivity13("")
+//
+// pgx connections, pools and transactions are supported natively by the
+// pgxadapter package, without the database/sql shim:
+//  statActivities, err := pgxadapter.Query(pool).StatActivity13("", pogo.LocksView)
+//
+// Hooks are notified of every query a runner issues, with the SQL, the queryable
+// tree it was generated from, its duration and the number of rows read. Hooks
+// logging with log/slog come with pogo, and hooks tracing with OpenTelemetry
+// come with the github.com/sanggonlee/pogo/otel module:
+//  statActivities, err := pogo.Query(sql.DB).
+//  	Hooks(pogo.NewSlogHooks(logger), pogootel.NewTracingHooks(nil)).
+//  	StatActivity13("")
+//
+// Queryables run repeatedly, e.g. by a poller, can be compiled once and
+// prepared as a statement, so that the queryable tree isn't walked again:
+//  compiled, err := pogo.StatActivityView.With(pogo.LocksView).Compile()
+//  stmt, err := pogo.Query(sql.DB).Prepare(compiled)
+//  defer stmt.Close()
+//  rows, err := stmt.RowsContext(ctx)
+//
+// Rows can also be scanned into your own structs, with the fields tagged with
+// the columns they map to. Joined rows map to slices of such structs:
+//  type Session struct {
+//  	PID   int64       `pogo:"pid"`
+//  	State null.String `pogo:"state"`
+//  	Locks []struct {
+//  		Mode string `pogo:"mode"`
+//  	} `pogo:"locks"`
+//  }
+//  queryable := pogo.StatActivityView.With(pogo.LocksView)
+//  scanner, err := pogo.NewStructScanner(queryable, Session{})
+//  rows, err := pogo.Query(sql.DB).For(queryable)
+//  var sessions []Session
+//  err = scanner.ScanAll(rows, &sessions)
+//
+// For tools displaying any queryable, ForMaps reads the rows into maps keyed by
+// column name, with the joined rows decoded into slices of maps:
+//  rows, err := pogo.Query(sql.DB).ForMaps(pogo.StatActivityView.With(pogo.LocksView))
+//
+// Relations pogo doesn't know about, such as an in-house monitoring view or a
+// view provided by an extension, can be registered as custom targets. They are
+// joined with With() like the built-in views:
+//  sessions, err := pogo.RegisterTarget(pogo.TargetDefinition{
+//  	Relation:   "monitoring.sessions",
+//  	KeyColumns: []string{"pid"},
+//  }, &Session{}, &SessionJoined{})
+//  err = pogo.RegisterJoin(pogo.StatActivityView.Target, pogo.Join{
+//  	Target: sessions.Target,
+//  	Column: "sessions",
+//  	On:     []pogo.JoinOn{{Parent: "pid", Child: "pid"}},
+//  })
+//  rows, err := pogo.Query(sql.DB).For(pogo.StatActivityView.With(sessions))
+//
 package pogo // import "github.com/sanggonlee/pogo"
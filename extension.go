@@ -0,0 +1,88 @@
+package pogo
+
+import (
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo/internal/query"
+)
+
+// ErrStatStatementsNotInstalled is returned by the queries on pg_stat_statements
+// when the extension isn't created in the database, wrapping the error of the
+// driver. errors.Is and errors.Cause recognize it.
+// The extension also has to be in shared_preload_libraries to collect statistics.
+var ErrStatStatementsNotInstalled = errors.New("pg_stat_statements extension is not installed, run CREATE EXTENSION pg_stat_statements")
+
+// sqlStateUndefinedTable is the SQLSTATE of a query on a relation that doesn't exist.
+const sqlStateUndefinedTable = "42P01"
+
+// sqlStater is implemented by the errors of the drivers reporting the SQLSTATE
+// of the server errors with a method, such as pgx.
+type sqlStater interface {
+	SQLState() string
+}
+
+// extensionError wraps err with ErrStatStatementsNotInstalled if it's the
+// server failing to find a relation in a query on pg_stat_statements, and
+// returns err otherwise.
+func extensionError(queryables []query.Queryable, err error) error {
+	if err == nil || !involves(queryables, query.TargetStatStatements) || errors.Is(err, ErrStatStatementsNotInstalled) {
+		return err
+	}
+
+	var state string
+	var pqErr *pq.Error
+	var stater sqlStater
+	if errors.As(err, &pqErr) {
+		state = string(pqErr.Code)
+	} else if errors.As(err, &stater) {
+		state = stater.SQLState()
+	}
+	if state != sqlStateUndefinedTable {
+		return err
+	}
+	return notInstalledError{err}
+}
+
+// involves reports whether any of the queryables or their joins are on the target.
+func involves(queryables []query.Queryable, target query.Target) bool {
+	for _, q := range queryables {
+		if q.Target == target || involves(q.Joins, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// extensionRows are the rows of a query on pg_stat_statements, for the drivers
+// reporting the server errors once the rows are read, such as pgx.
+type extensionRows struct {
+	Rows
+
+	queryables []query.Queryable
+}
+
+func (r extensionRows) Err() error {
+	return extensionError(r.queryables, r.Rows.Err())
+}
+
+// notInstalledError is the error of the driver for a query on an extension
+// that isn't installed, which is ErrStatStatementsNotInstalled.
+type notInstalledError struct {
+	err error
+}
+
+func (e notInstalledError) Error() string {
+	return ErrStatStatementsNotInstalled.Error() + ": " + e.err.Error()
+}
+
+func (e notInstalledError) Unwrap() error {
+	return e.err
+}
+
+func (e notInstalledError) Is(target error) bool {
+	return target == ErrStatStatementsNotInstalled
+}
+
+func (e notInstalledError) Cause() error {
+	return ErrStatStatementsNotInstalled
+}
@@ -0,0 +1,128 @@
+package pogo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sanggonlee/pogo/internal/query"
+)
+
+// Hooks is notified of every query pogo runs, e.g. for logging or tracing.
+// Set them on a runner with QueryRunner.Hooks.
+type Hooks interface {
+	// BeforeQuery is called before running the query with its arguments. The
+	// context it returns is passed to AfterQuery, and to the query itself.
+	BeforeQuery(ctx context.Context, sql string, args []interface{}) context.Context
+
+	// AfterQuery is called once the query is done. For the rows read by pogo
+	// or returned by QueryRunner.Rows, it's when the rows are closed, with the
+	// number of rows scanned and the first error met reading them. For the
+	// rows returned by QueryRunner.For, it's when the query returns, with -1
+	// rows since they are read by the caller.
+	AfterQuery(ctx context.Context, sql string, duration time.Duration, rows int, err error)
+}
+
+type queryablesKey struct{}
+
+// QueryablesFromContext returns the queryables the query being run was
+// generated from, in the context given to Hooks. It's a single queryable,
+// except for snapshots.
+func QueryablesFromContext(ctx context.Context) []query.Queryable {
+	queryables, _ := ctx.Value(queryablesKey{}).([]query.Queryable)
+	return queryables
+}
+
+// QueryDescriptionFromContext describes the queryables the query being run
+// was generated from, in the context given to Hooks. See Queryable.Describe.
+// The queryables of snapshots are separated by semicolons.
+func QueryDescriptionFromContext(ctx context.Context) string {
+	queryables := QueryablesFromContext(ctx)
+	descriptions := make([]string, 0, len(queryables))
+	for _, q := range queryables {
+		descriptions = append(descriptions, q.Describe())
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// Hooks adds hooks notified of the queries run by the runner.
+func (qr QueryRunner) Hooks(hooks ...Hooks) QueryRunner {
+	_qr := qr
+	_qr.hooks = append(append([]Hooks{}, qr.hooks...), hooks...)
+	return _qr
+}
+
+// beforeQuery calls the hooks before running the query generated from the
+// queryables with args, and returns the context for the query along with its
+// start time.
+func (qr QueryRunner) beforeQuery(queryables []query.Queryable, q string, args []interface{}) (context.Context, time.Time) {
+	ctx := qr.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, queryablesKey{}, queryables)
+	for _, h := range qr.hooks {
+		ctx = h.BeforeQuery(ctx, q, args)
+	}
+	return ctx, time.Now()
+}
+
+// afterQuery calls the hooks after running the query, in the reverse order.
+func (qr QueryRunner) afterQuery(ctx context.Context, q string, start time.Time, rows int, err error) {
+	duration := time.Since(start)
+	for i := len(qr.hooks) - 1; i >= 0; i-- {
+		qr.hooks[i].AfterQuery(ctx, q, duration, rows, err)
+	}
+}
+
+// hookedRows counts the rows scanned and calls the hooks once closed, with
+// the first error met reading them.
+type hookedRows struct {
+	Rows
+
+	runner  QueryRunner
+	ctx     context.Context
+	query   string
+	start   time.Time
+	numRows int
+	scanned bool
+	scanErr error
+	closed  bool
+}
+
+func (r *hookedRows) Next() bool {
+	r.scanned = false
+	return r.Rows.Next()
+}
+
+func (r *hookedRows) Scan(dest ...interface{}) error {
+	if err := r.Rows.Scan(dest...); err != nil {
+		if r.scanErr == nil {
+			r.scanErr = err
+		}
+		return err
+	}
+	if !r.scanned {
+		r.scanned = true
+		r.numRows++
+	}
+	return nil
+}
+
+func (r *hookedRows) Close() error {
+	err := r.Rows.Close()
+	if r.closed {
+		return err
+	}
+	r.closed = true
+
+	hookErr := r.Rows.Err()
+	if hookErr == nil {
+		hookErr = r.scanErr
+	}
+	if hookErr == nil {
+		hookErr = err
+	}
+	r.runner.afterQuery(r.ctx, r.query, r.start, r.numRows, hookErr)
+	return err
+}
@@ -0,0 +1,50 @@
+//go:build go1.21
+// +build go1.21
+
+package pogo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogHooks logs every query pogo runs with a slog.Logger: the SQL and the
+// queryable tree at debug level before running it, and the duration and
+// number of rows at debug level after, or at error level if it failed.
+type SlogHooks struct {
+	Logger *slog.Logger
+}
+
+// NewSlogHooks returns hooks logging with logger, or slog.Default() if nil.
+func NewSlogHooks(logger *slog.Logger) SlogHooks {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return SlogHooks{Logger: logger}
+}
+
+// BeforeQuery logs the query about to run.
+func (h SlogHooks) BeforeQuery(ctx context.Context, sql string, args []interface{}) context.Context {
+	h.Logger.DebugContext(ctx, "pogo query",
+		slog.String("queryable", QueryDescriptionFromContext(ctx)),
+		slog.String("sql", sql),
+		slog.Any("args", args),
+	)
+	return ctx
+}
+
+// AfterQuery logs the outcome of the query.
+func (h SlogHooks) AfterQuery(ctx context.Context, sql string, duration time.Duration, rows int, err error) {
+	attrs := []slog.Attr{
+		slog.String("queryable", QueryDescriptionFromContext(ctx)),
+		slog.Duration("duration", duration),
+		slog.Int("rows", rows),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("sql", sql), slog.Any("error", err))
+		h.Logger.LogAttrs(ctx, slog.LevelError, "pogo query failed", attrs...)
+		return
+	}
+	h.Logger.LogAttrs(ctx, slog.LevelDebug, "pogo query done", attrs...)
+}
@@ -11,10 +11,12 @@ func JSONScan(dest, value interface{}) error {
 		return nil
 	}
 
-	if v, ok := value.([]byte); ok {
-		if err := json.Unmarshal(v, dest); err != nil {
-			return err
-		}
+	// database/sql drivers give JSON as bytes, while pgx gives it as a string.
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
 	}
 
 	return nil
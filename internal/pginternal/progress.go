@@ -0,0 +1,12 @@
+package pginternal
+
+import "gopkg.in/guregu/null.v3"
+
+// Percent returns done as a percentage of total, as reported by the progress
+// views. It's null if either is null, or total is zero.
+func Percent(done, total BigInt) null.Float {
+	if !done.Valid || !total.Valid || total.Int64 == 0 {
+		return null.Float{}
+	}
+	return null.FloatFrom(float64(done.Int64) / float64(total.Int64) * 100)
+}
@@ -1,14 +1,33 @@
 package pginternal
 
-import "gopkg.in/guregu/null.v3"
+import (
+	"github.com/lib/pq"
+	"gopkg.in/guregu/null.v3"
+)
 
 // LSN represetns a pg_lsn type.
-type LSN null.String
+// The null type is embedded so that its Scan and JSON methods are kept.
+type LSN struct {
+	null.String
+}
 
 // OID represents a oid type in Postgres.
-type OID null.Int
+type OID struct {
+	null.Int
+}
 
 // BigInt represents a bigint type in Postgres.
 // NOTE: null.Int does not span the entire range of Postgres' bigint type.
 // TODO: consider big.Int
-type BigInt null.Int
+type BigInt struct {
+	null.Int
+}
+
+// Aggregate holds the rows of a joined relation aggregated into a scalar value.
+// Only the field of the aggregation asked for is set.
+type Aggregate struct {
+	Count  null.Int       `json:"count"`
+	Exists null.Bool      `json:"exists"`
+	Sum    null.Float     `json:"sum"`
+	Array  pq.StringArray `json:"array"`
+}
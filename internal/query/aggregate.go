@@ -0,0 +1,141 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/sanggonlee/pogo/internal/pginternal"
+)
+
+// Aggregation kinds, named after the suffix of the column they yield.
+const (
+	aggregateCount  = "count"
+	aggregateExists = "exists"
+	aggregateSum    = "sum"
+	aggregateArray  = "array"
+)
+
+// aggregation describes how the rows of a joined queryable are reduced into
+// a scalar value, rather than into a JSON array of the rows.
+type aggregation struct {
+	kind   string
+	column string
+}
+
+// Count aggregates the joined rows into their number.
+func (q Queryable) Count() Queryable {
+	_q := q
+	_q.aggregate = aggregation{kind: aggregateCount}
+	return _q
+}
+
+// Exists aggregates the joined rows into whether there is any.
+func (q Queryable) Exists() Queryable {
+	_q := q
+	_q.aggregate = aggregation{kind: aggregateExists}
+	return _q
+}
+
+// Sum aggregates the joined rows into the sum of the column.
+func (q Queryable) Sum(column string) Queryable {
+	_q := q
+	_q.aggregate = aggregation{kind: aggregateSum, column: column}
+	return _q
+}
+
+// ArrayAgg aggregates the joined rows into an array of the column,
+// with the values converted to text.
+func (q Queryable) ArrayAgg(column string) Queryable {
+	_q := q
+	_q.aggregate = aggregation{kind: aggregateArray, column: column}
+	return _q
+}
+
+// Aggregated reports whether the queryable is aggregated into a scalar value
+// when joined.
+func (q Queryable) Aggregated() bool {
+	return q.aggregate.kind != ""
+}
+
+// Destination returns where to scan the queryable joined under another one:
+// rows if the joined rows are selected as they are, or the field of agg
+// matching the aggregation otherwise.
+func (q Queryable) Destination(rows interface{}, agg *pginternal.Aggregate) interface{} {
+	switch q.aggregate.kind {
+	case aggregateCount:
+		return &agg.Count
+	case aggregateExists:
+		return &agg.Exists
+	case aggregateSum:
+		return &agg.Sum
+	case aggregateArray:
+		return &agg.Array
+	default:
+		return rows
+	}
+}
+
+func (q Queryable) validateAggregate() error {
+	if q.aggregate.column == "" {
+		return nil
+	}
+
+	var all []string
+	if q.Specifier != nil {
+		all = q.Specifier.Selects()
+	}
+	if len(missingColumns(all, []string{q.aggregate.column})) > 0 {
+		return fmt.Errorf("column %s is not aggregatable for %s", q.aggregate.column, q.Target)
+	}
+	return nil
+}
+
+// aggregateColumns returns the columns the aggregation reads.
+func (q Queryable) aggregateColumns() []string {
+	if q.aggregate.column == "" {
+		return nil
+	}
+	return []string{q.aggregate.column}
+}
+
+// aggregateColumnName returns the name of the column the joined rows are
+// aggregated into. At the top level, each aggregation yields a scalar column
+// suffixed with its kind, e.g. locks_count. Nested in the rows of a parent,
+// it yields a JSON object keyed by its kind, e.g. locks_aggregate: {"count": 1},
+// which decodes into pginternal.Aggregate.
+func (q Queryable) aggregateColumnName(column string, nested bool) string {
+	switch {
+	case !q.Aggregated():
+		return column
+	case nested:
+		return column + "_aggregate"
+	default:
+		return column + "_" + q.aggregate.kind
+	}
+}
+
+// aggregateExpr returns the expression aggregating the rows under alias, with
+// row being the expression for a whole row. When grouped is set, the rows come
+// from a left join and alias is null for the parents without joined rows.
+func (q Queryable) aggregateExpr(alias, row string, grouped, nested bool) string {
+	var expr string
+	switch q.aggregate.kind {
+	case aggregateCount:
+		expr = fmt.Sprintf("count(%s)", alias)
+	case aggregateExists:
+		expr = fmt.Sprintf("count(%s) > 0", alias)
+	case aggregateSum:
+		expr = fmt.Sprintf("COALESCE(sum(%s.%s), 0)", alias, q.aggregate.column)
+	case aggregateArray:
+		expr = fmt.Sprintf("COALESCE(array_remove(array_agg(%s.%s::text), NULL), '{}')", alias, q.aggregate.column)
+	default:
+		if grouped {
+			return fmt.Sprintf("CASE WHEN count(%s) = 0 THEN '[]' ELSE json_agg(%s) END", alias, row)
+		}
+		return fmt.Sprintf("COALESCE(json_agg(%s), '[]')", row)
+	}
+
+	if nested {
+		return fmt.Sprintf("json_build_object('%s', %s)", q.aggregate.kind, expr)
+	}
+	return expr
+}
@@ -0,0 +1,466 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// JoinStrategy determines the shape of the SQL generated for the joined queryables.
+// Both strategies yield the same JSON shape for the joined rows.
+type JoinStrategy int
+
+// Join strategies defined
+const (
+	// JoinStrategyDefault inherits the strategy of the parent queryable,
+	// which is JoinStrategyGroupBy at the top level.
+	JoinStrategyDefault JoinStrategy = iota
+
+	// JoinStrategyGroupBy left joins the children and aggregates them with
+	// json_agg, grouping the parent rows by all of their columns.
+	JoinStrategyGroupBy
+
+	// JoinStrategyLateral aggregates the children of each parent row in a
+	// correlated LEFT JOIN LATERAL subquery, so the parent rows aren't grouped.
+	// It's cheaper for parents with wide columns, such as pg_stat_activity.query.
+	JoinStrategyLateral
+)
+
+// builder generates the SQL for a tree of queryables.
+type builder struct {
+	numAliases        int
+	excludeOwnBackend bool
+
+	// args are the arguments bound to the placeholders of the query.
+	args []interface{}
+}
+
+var nonIdentifierChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// nextAlias hands out a relation alias that is unique within the query,
+// so that the same relation can appear at any depth of the join tree.
+func (b *builder) nextAlias(relation string) string {
+	b.numAliases++
+	return fmt.Sprintf("%s_%d", nonIdentifierChars.ReplaceAllString(strings.ToLower(relation), "_"), b.numAliases)
+}
+
+// bind adds v to the arguments of the query, returning its placeholder.
+func (b *builder) bind(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// subquery is a generated query along with the names of the columns it yields,
+// not counting the hidden ones.
+type subquery struct {
+	sql     string
+	columns []string
+}
+
+// build generates the query for q, aliasing its relation with alias.
+// conds are the extra conditions q's rows must satisfy, such as the
+// correlation with the parent row. hidden are the extra columns selected
+// for the parent to join on, which are left out of the aggregated rows.
+// nested is set when the rows of q are aggregated into a parent row.
+func (b *builder) build(q Queryable, alias string, strategy JoinStrategy, conds, hidden []string, nested bool) (subquery, error) {
+	if q.Target == TargetUnspecified || (!q.SelectOnly && q.Specifier == nil) {
+		return subquery{}, q.getUnsupportedTargetError()
+	}
+
+	if q.strategy != JoinStrategyDefault {
+		strategy = q.strategy
+	}
+	if strategy == JoinStrategyDefault {
+		strategy = JoinStrategyGroupBy
+	}
+
+	columns, err := q.selects()
+	if err != nil {
+		return subquery{}, err
+	}
+	if err := q.validateOrder(); err != nil {
+		return subquery{}, err
+	}
+	if err := q.validateAggregate(); err != nil {
+		return subquery{}, err
+	}
+	if err := q.validateScope(); err != nil {
+		return subquery{}, err
+	}
+	selects := q.selectClauses(alias, append(columns, hidden...))
+	if q.where != "" {
+		conds = append([]string{q.realias(q.where, alias)}, conds...)
+	}
+	if cond := b.backendCondition(q.Target, alias); cond != "" {
+		conds = append(conds, cond)
+	}
+	if len(q.after) > 0 {
+		after, err := b.afterCondition(q, alias)
+		if err != nil {
+			return subquery{}, err
+		}
+		conds = append(conds, after)
+	}
+
+	// The rows are grouped only if any of the joined queryables is aggregated
+	// over the groups. The ones correlated with each row then have to refer
+	// to grouped columns only. They're also grouped by the columns identifying
+	// them, so that the rows left with the same projected columns aren't merged.
+	grouped := false
+	for _, j := range q.Joins {
+		if !j.SelectOnly && !correlated(j, strategy) {
+			grouped = true
+		}
+	}
+	var groupBys []string
+	if grouped {
+		groupBys = append(groupBys, q.columnExprs(alias, append(columns, hidden...))...)
+		groupBys = append(groupBys, q.columnExprs(alias, q.rowColumns())...)
+		groupBys = append(groupBys, q.columnExprs(alias, q.orderColumns())...)
+	}
+
+	joins := make([]string, 0, len(q.Joins))
+	for _, j := range q.Joins {
+		join, err := lookupJoin(q.Target, j.Target)
+		if err != nil {
+			return subquery{}, errors.Wrap(err, "getting join clauses")
+		}
+		if j.required {
+			if j.SelectOnly {
+				return subquery{}, fmt.Errorf("select-only queryable %s cannot be required", j.Target)
+			}
+			exists, err := b.existsCondition(j, join, alias)
+			if err != nil {
+				return subquery{}, errors.Wrapf(err, "converting required condition for %s under %s", j.Target, q.Target)
+			}
+			conds = append(conds, exists)
+		}
+
+		if j.SelectOnly {
+			columns = append(columns, join.Column)
+			def, _ := lookupTarget(j.Target)
+			selects = append(selects, join.functionClause(def.Relation, alias))
+			if grouped {
+				groupBys = append(groupBys, join.argExprs(alias)...)
+			}
+			continue
+		}
+
+		column := j.aggregateColumnName(join.Column, nested)
+		columns = append(columns, column)
+
+		joinAlias := b.nextAlias(j.relation())
+		var joinSelect, joinClause string
+		if correlated(j, strategy) {
+			joinSelect, joinClause, err = b.lateralJoin(j, join, column, alias, joinAlias, strategy, grouped, nested)
+			if grouped {
+				groupBys = append(groupBys, qualify(alias, join.parentColumns())...)
+			}
+		} else {
+			joinSelect, joinClause, err = b.groupByJoin(j, join, column, alias, joinAlias, strategy, nested)
+		}
+		if err != nil {
+			return subquery{}, errors.Wrapf(err, "converting join query for %s under %s", j.Target, q.Target)
+		}
+
+		selects = append(selects, joinSelect)
+		if joinClause != "" {
+			joins = append(joins, joinClause)
+		}
+	}
+
+	var groupBy string
+	if len(groupBys) > 0 {
+		groupBy = fmt.Sprintf("GROUP BY %s", strings.Join(dedupe(groupBys), ", "))
+	}
+
+	sql := fmt.Sprintf(`SELECT
+		%s
+		FROM %s AS %s
+		%s
+		%s
+		%s
+		%s
+		%s`,
+		strings.Join(selects, ", "),
+		q.relation(),
+		alias,
+		strings.Join(joins, "\n"),
+		whereClause(conds),
+		groupBy,
+		q.orderByClause(alias),
+		q.limitClause(),
+	)
+
+	return subquery{sql: sql, columns: columns}, nil
+}
+
+// rowColumns returns the columns telling the rows of q apart whatever its
+// projection: the key columns of its target, or else all the columns of its
+// Specifier.
+func (q Queryable) rowColumns() []string {
+	if def, ok := lookupTarget(q.Target); ok && len(def.KeyColumns) > 0 {
+		return def.KeyColumns
+	}
+	if q.Specifier == nil {
+		return nil
+	}
+	return q.Specifier.Selects()
+}
+
+// groupByJoin left joins the child query and aggregates its rows into column
+// over the groups of the parent rows.
+func (b *builder) groupByJoin(j Queryable, join Join, column, parentAlias, alias string, strategy JoinStrategy, nested bool) (string, string, error) {
+	projected, err := j.selects()
+	if err != nil {
+		return "", "", err
+	}
+	hidden := dedupe(missingColumns(projected, append(join.childColumns(), j.aggregateColumns()...)))
+
+	joinQuery, err := b.build(j, alias, strategy, nil, hidden, true)
+	if err != nil {
+		return "", "", err
+	}
+
+	// The rows are aggregated as they are, unless columns to join on had to
+	// be added to them.
+	row := alias
+	if len(hidden) > 0 {
+		row = fmt.Sprintf(
+			"(SELECT %[1]s_row FROM (SELECT %[2]s) AS %[1]s_row)",
+			alias,
+			strings.Join(qualify(alias, joinQuery.columns), ", "),
+		)
+	}
+
+	selectClause := fmt.Sprintf("(%s) AS %s", j.aggregateExpr(alias, row, true, nested), column)
+	joinClause := fmt.Sprintf(`LEFT JOIN (
+				%s
+			) AS %s ON %s`, joinQuery.sql, alias, join.condition(parentAlias, alias))
+
+	return selectClause, joinClause, nil
+}
+
+// lateralJoin aggregates the child rows correlated with each parent row into
+// column in a lateral subquery, or in a scalar subquery when the parent rows
+// are grouped.
+func (b *builder) lateralJoin(j Queryable, join Join, column, parentAlias, alias string, strategy JoinStrategy, grouped, nested bool) (string, string, error) {
+	joinQuery, err := b.build(j, alias, strategy, []string{join.condition(parentAlias, alias)}, nil, true)
+	if err != nil {
+		return "", "", err
+	}
+
+	aggregate := fmt.Sprintf(`SELECT %[1]s AS %[2]s
+				FROM (
+					%[3]s
+				) AS %[4]s`, j.aggregateExpr(alias, alias, false, nested), column, joinQuery.sql, alias)
+
+	if grouped {
+		return fmt.Sprintf("(%s) AS %s", aggregate, column), "", nil
+	}
+
+	aggAlias := alias + "_agg"
+	selectClause := fmt.Sprintf("%s.%s", aggAlias, column)
+	joinClause := fmt.Sprintf(`LEFT JOIN LATERAL (
+				%s
+			) AS %s ON true`, aggregate, aggAlias)
+
+	return selectClause, joinClause, nil
+}
+
+// existsCondition returns the condition for the parent rows having any rows of j
+// joined, recursively including the conditions of the joins required under j.
+func (b *builder) existsCondition(j Queryable, join Join, parentAlias string) (string, error) {
+	if err := j.validateScope(); err != nil {
+		return "", err
+	}
+
+	alias := b.nextAlias(j.relation())
+	conds := []string{join.condition(parentAlias, alias)}
+	if j.where != "" {
+		conds = append(conds, j.realias(j.where, alias))
+	}
+	if cond := b.backendCondition(j.Target, alias); cond != "" {
+		conds = append(conds, cond)
+	}
+
+	for _, child := range j.Joins {
+		if !child.required {
+			continue
+		}
+		if child.SelectOnly {
+			return "", fmt.Errorf("select-only queryable %s cannot be required", child.Target)
+		}
+		childJoin, err := lookupJoin(j.Target, child.Target)
+		if err != nil {
+			return "", errors.Wrap(err, "getting join clauses")
+		}
+		exists, err := b.existsCondition(child, childJoin, alias)
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, exists)
+	}
+
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s AS %s %s)", j.relation(), alias, whereClause(conds)), nil
+}
+
+// backendCondition returns the condition leaving out the rows of the backend
+// running the query, if asked for and t has a backend PID column.
+func (b *builder) backendCondition(t Target, alias string) string {
+	if !b.excludeOwnBackend {
+		return ""
+	}
+	def, ok := lookupTarget(t)
+	if !ok || def.BackendPIDColumn == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s IS DISTINCT FROM pg_backend_pid()", alias, def.BackendPIDColumn)
+}
+
+func whereClause(conds []string) string {
+	switch len(conds) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("WHERE %s", conds[0])
+	default:
+		return fmt.Sprintf("WHERE (%s)", strings.Join(conds, ") AND ("))
+	}
+}
+
+// correlated reports whether the rows of j are aggregated in a subquery
+// correlated with each parent row, rather than over the groups of parent rows.
+// Rows to be ordered or limited per parent row need the correlation.
+func correlated(j Queryable, strategy JoinStrategy) bool {
+	return strategy == JoinStrategyLateral || j.paginated()
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	deduped := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			deduped = append(deduped, v)
+		}
+	}
+	return deduped
+}
+
+// missingColumns returns the columns of want that are not in have.
+func missingColumns(have, want []string) []string {
+	var missing []string
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if h == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
+
+func qualify(alias string, columns []string) []string {
+	qualified := make([]string, 0, len(columns))
+	for _, c := range columns {
+		qualified = append(qualified, fmt.Sprintf("%s.%s", alias, c))
+	}
+	return qualified
+}
+
+// realias rewrites the columns qualified with the relation name in a user given
+// clause, so that they refer to the alias the relation got in the query. String
+// literals and quoted identifiers are left as they are.
+func realias(clause, relation, alias string) string {
+	if relation == alias {
+		return clause
+	}
+	re := regexp.MustCompile(`(^|[^\w.])` + regexp.QuoteMeta(relation) + `\.`)
+
+	var b strings.Builder
+	for _, s := range splitQuoted(clause) {
+		if s.quoted {
+			b.WriteString(s.text)
+		} else {
+			b.WriteString(re.ReplaceAllString(s.text, "${1}"+alias+"."))
+		}
+	}
+	return b.String()
+}
+
+type clauseSection struct {
+	text   string
+	quoted bool
+}
+
+// splitQuoted splits a clause into its quoted sections, i.e. the string
+// literals, the quoted identifiers and the dollar quoted strings, and the
+// sections in between.
+func splitQuoted(clause string) []clauseSection {
+	var sections []clauseSection
+	start := 0
+	for i := 0; i < len(clause); {
+		end := quotedEnd(clause, i)
+		if end < 0 {
+			i++
+			continue
+		}
+		if start < i {
+			sections = append(sections, clauseSection{text: clause[start:i]})
+		}
+		sections = append(sections, clauseSection{text: clause[i:end], quoted: true})
+		start, i = end, end
+	}
+	if start < len(clause) {
+		sections = append(sections, clauseSection{text: clause[start:]})
+	}
+	return sections
+}
+
+var dollarQuote = regexp.MustCompile(`^\$(?:[A-Za-z_]\w*)?\$`)
+
+// quotedEnd returns the end of the quoted section starting at i, or -1 if none
+// starts there. An unterminated one runs to the end of the clause.
+func quotedEnd(clause string, i int) int {
+	switch c := clause[i]; c {
+	case '\'', '"':
+		// Backslashes only escape in E'...' strings.
+		escapes := c == '\'' && i > 0 && (clause[i-1] == 'E' || clause[i-1] == 'e') &&
+			(i == 1 || !isWordByte(clause[i-2]))
+		for j := i + 1; j < len(clause); j++ {
+			switch {
+			case escapes && clause[j] == '\\':
+				j++
+			case clause[j] == c:
+				if j+1 < len(clause) && clause[j+1] == c {
+					j++
+					continue
+				}
+				return j + 1
+			}
+		}
+		return len(clause)
+	case '$':
+		tag := dollarQuote.FindString(clause[i:])
+		if tag == "" {
+			return -1
+		}
+		if k := strings.Index(clause[i+len(tag):], tag); k >= 0 {
+			return i + len(tag) + k + len(tag)
+		}
+		return len(clause)
+	}
+	return -1
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,64 @@
+package query
+
+// Compiled is a queryable converted to SQL once, so that it can be run any
+// number of times without walking the queryable tree again.
+type Compiled struct {
+	queryable Queryable
+	sql       string
+	args      []interface{}
+
+	// selected tells which of the Specifier's columns are selected, or is
+	// nil if they all are.
+	selected []bool
+}
+
+// Compile converts the queryable to SQL, and works out how its rows are scanned.
+func (q Queryable) Compile() (Compiled, error) {
+	sql, args, err := q.ToQueryArgs()
+	if err != nil {
+		return Compiled{}, err
+	}
+
+	c := Compiled{queryable: q, sql: sql, args: args}
+	if len(q.columns) > 0 && q.Specifier != nil {
+		selected := q.selectedColumns()
+		all := q.Specifier.Selects()
+		c.selected = make([]bool, len(all))
+		for i, column := range all {
+			c.selected[i] = selected[column]
+		}
+	}
+	return c, nil
+}
+
+// Queryable returns the queryable the query was compiled from.
+func (c Compiled) Queryable() Queryable {
+	return c.queryable
+}
+
+// SQL returns the compiled query.
+func (c Compiled) SQL() string {
+	return c.sql
+}
+
+// Args returns the arguments to run the compiled query with.
+func (c Compiled) Args() []interface{} {
+	return c.args
+}
+
+// ScanDestinations is like Queryable.ScanDestinations, without working out
+// the selected columns again.
+func (c Compiled) ScanDestinations(s Scannable) []interface{} {
+	dests := s.ScanDestinations(c.queryable.Joins)
+	if c.selected == nil {
+		return dests
+	}
+
+	projected := make([]interface{}, 0, len(dests))
+	for i, d := range dests {
+		if i >= len(c.selected) || c.selected[i] {
+			projected = append(projected, d)
+		}
+	}
+	return projected
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Describe returns a compact description of the queryable tree, e.g.
+//  pg_stat_activity(where: state = 'active')[pg_locks(count, required), pg_blocking_pids]
+// It's meant for logs and traces, to tell which queryable a query was
+// generated from without reading the SQL.
+func (q Queryable) Describe() string {
+	var b strings.Builder
+	q.describe(&b)
+	return b.String()
+}
+
+func (q Queryable) describe(b *strings.Builder) {
+	def, ok := lookupTarget(q.Target)
+	if ok && q.scope != ScopeUser {
+		b.WriteString(q.relation())
+	} else if ok {
+		b.WriteString(def.Relation)
+	} else {
+		fmt.Fprintf(b, "target(%d)", q.Target)
+	}
+
+	var modifiers []string
+	if q.where != "" {
+		modifiers = append(modifiers, "where: "+q.where)
+	}
+	if len(q.columns) > 0 {
+		modifiers = append(modifiers, "columns: "+strings.Join(q.columns, " "))
+	}
+	if len(q.orderBy) > 0 {
+		orders := make([]string, 0, len(q.orderBy))
+		for _, o := range q.orderBy {
+			if o.Descending {
+				orders = append(orders, o.Column+" desc")
+			} else {
+				orders = append(orders, o.Column)
+			}
+		}
+		modifiers = append(modifiers, "order: "+strings.Join(orders, " "))
+	}
+	if len(q.after) > 0 {
+		modifiers = append(modifiers, "after cursor")
+	}
+	if q.limit > 0 {
+		modifiers = append(modifiers, fmt.Sprintf("limit: %d", q.limit))
+	}
+	if q.aggregate.kind != "" {
+		if q.aggregate.column != "" {
+			modifiers = append(modifiers, fmt.Sprintf("%s: %s", q.aggregate.kind, q.aggregate.column))
+		} else {
+			modifiers = append(modifiers, q.aggregate.kind)
+		}
+	}
+	if q.required {
+		modifiers = append(modifiers, "required")
+	}
+	if q.strategy == JoinStrategyLateral {
+		modifiers = append(modifiers, "lateral")
+	}
+	if len(modifiers) > 0 {
+		fmt.Fprintf(b, "(%s)", strings.Join(modifiers, ", "))
+	}
+
+	if len(q.Joins) == 0 {
+		return
+	}
+	b.WriteString("[")
+	for i, j := range q.Joins {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		j.describe(b)
+	}
+	b.WriteString("]")
+}
@@ -16,3 +16,11 @@ func (j joiner) GetUnsupportedJoinError() error {
 		j.join,
 	)
 }
+
+func (j joiner) GetAlreadyRegisteredJoinError() error {
+	return fmt.Errorf(
+		"join between %s and %s is already registered",
+		j.from,
+		j.join,
+	)
+}
@@ -0,0 +1,54 @@
+package query
+
+import "github.com/pkg/errors"
+
+// Layout describes the columns of the rows of a queryable, in the order they
+// are selected, along with the layouts of the rows joined into them.
+type Layout struct {
+	Columns []string
+
+	// Joins maps the columns holding joined rows as JSON arrays to the
+	// layouts of these rows. Aggregated and select-only joins are left out,
+	// since their columns hold scalar values.
+	Joins map[string]Layout
+}
+
+// Layout returns the layout of the rows of the queryable.
+func (q Queryable) Layout() (Layout, error) {
+	return q.layout(false)
+}
+
+func (q Queryable) layout(nested bool) (Layout, error) {
+	selects, err := q.selects()
+	if err != nil {
+		return Layout{}, err
+	}
+
+	l := Layout{
+		Columns: append([]string{}, selects...),
+		Joins:   make(map[string]Layout),
+	}
+	for _, j := range q.Joins {
+		join, err := lookupJoin(q.Target, j.Target)
+		if err != nil {
+			return Layout{}, errors.Wrap(err, "getting join clauses")
+		}
+		if j.SelectOnly {
+			l.Columns = append(l.Columns, join.Column)
+			continue
+		}
+
+		column := j.aggregateColumnName(join.Column, nested)
+		l.Columns = append(l.Columns, column)
+		if j.Aggregated() {
+			continue
+		}
+
+		joinLayout, err := j.layout(true)
+		if err != nil {
+			return Layout{}, err
+		}
+		l.Joins[column] = joinLayout
+	}
+	return l, nil
+}
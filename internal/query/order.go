@@ -0,0 +1,166 @@
+package query
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Order is an ordering on a column of a queryable.
+type Order struct {
+	Column     string
+	Descending bool
+}
+
+// Asc orders by the column in ascending order.
+func Asc(column string) Order {
+	return Order{Column: column}
+}
+
+// Desc orders by the column in descending order.
+func Desc(column string) Order {
+	return Order{Column: column, Descending: true}
+}
+
+// OrderBy orders the rows of the queryable by the given columns. When the
+// queryable is joined under another one, its rows are ordered within each
+// parent row.
+func (q Queryable) OrderBy(orders ...Order) Queryable {
+	_q := q
+	_q.orderBy = orders
+	return _q
+}
+
+// Limit limits the number of rows of the queryable to n. When the queryable
+// is joined under another one, it limits the number of rows per parent row.
+func (q Queryable) Limit(n int) Queryable {
+	_q := q
+	_q.limit = n
+	return _q
+}
+
+// After restricts the rows of the queryable to the ones coming after the
+// cursor in the order set by OrderBy, for keyset pagination. The cursor holds
+// a value for each of the ordered columns, typically taken from the last row
+// of the previous page. Rows with null values in the ordered columns never
+// come after a cursor. The values are bound as arguments of the query, see
+// ToQueryArgs.
+func (q Queryable) After(cursor ...interface{}) Queryable {
+	_q := q
+	_q.after = cursor
+	return _q
+}
+
+// paginated reports whether the rows of q have to be ordered or limited.
+func (q Queryable) paginated() bool {
+	return len(q.orderBy) > 0 || q.limit > 0 || len(q.after) > 0
+}
+
+// validateOrder ensures the ordering of q refers to its columns.
+func (q Queryable) validateOrder() error {
+	if len(q.after) > 0 && len(q.after) != len(q.orderBy) {
+		return fmt.Errorf(
+			"cursor of %d values given for %d ordered columns of %s",
+			len(q.after),
+			len(q.orderBy),
+			q.Target,
+		)
+	}
+	if q.limit < 0 {
+		return fmt.Errorf("negative limit given for %s", q.Target)
+	}
+	if len(q.orderBy) == 0 {
+		return nil
+	}
+
+	var all []string
+	if q.Specifier != nil {
+		all = q.Specifier.Selects()
+	}
+	for _, o := range q.orderBy {
+		if len(missingColumns(all, []string{o.Column})) > 0 {
+			return fmt.Errorf("column %s is not orderable for %s", o.Column, q.Target)
+		}
+	}
+	return nil
+}
+
+func (q Queryable) orderByClause(alias string) string {
+	if len(q.orderBy) == 0 {
+		return ""
+	}
+
+	orders := make([]string, 0, len(q.orderBy))
+	for _, o := range q.orderBy {
+		direction := "ASC"
+		if o.Descending {
+			direction = "DESC"
+		}
+		orders = append(orders, fmt.Sprintf("%s %s", q.columnExpr(alias, o.Column), direction))
+	}
+	return fmt.Sprintf("ORDER BY %s", strings.Join(orders, ", "))
+}
+
+func (q Queryable) limitClause() string {
+	if q.limit == 0 {
+		return ""
+	}
+	return fmt.Sprintf("LIMIT %d", q.limit)
+}
+
+// afterCondition returns the condition for the rows of q coming after the
+// cursor, e.g. (a > $1) OR (a = $1 AND b < $2) for ordering by a ascending,
+// b descending. The cursor values are bound as arguments of the query.
+func (b *builder) afterCondition(q Queryable, alias string) (string, error) {
+	placeholders := make([]string, 0, len(q.after))
+	for k, v := range q.after {
+		value, err := cursorValue(v)
+		if err != nil {
+			return "", errors.Wrapf(err, "converting cursor value for %s", q.orderBy[k].Column)
+		}
+		placeholders = append(placeholders, b.bind(value))
+	}
+
+	var disjuncts []string
+	for i, o := range q.orderBy {
+		conjuncts := make([]string, 0, i+1)
+		for k := 0; k <= i; k++ {
+			op := "="
+			if k == i {
+				op = ">"
+				if o.Descending {
+					op = "<"
+				}
+			}
+			conjuncts = append(conjuncts, fmt.Sprintf("%s %s %s", q.columnExpr(alias, q.orderBy[k].Column), op, placeholders[k]))
+		}
+		disjuncts = append(disjuncts, fmt.Sprintf("(%s)", strings.Join(conjuncts, " AND ")))
+	}
+	return strings.Join(disjuncts, " OR "), nil
+}
+
+func (q Queryable) orderColumns() []string {
+	columns := make([]string, 0, len(q.orderBy))
+	for _, o := range q.orderBy {
+		columns = append(columns, o.Column)
+	}
+	return columns
+}
+
+// cursorValue returns the value bound for a cursor value, which Postgres
+// coerces to the type of the column it's compared with. Null values never
+// compare, so they're rejected.
+func cursorValue(v interface{}) (interface{}, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+	if v == nil {
+		return nil, errors.New("null cursor value")
+	}
+	return v, nil
+}
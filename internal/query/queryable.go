@@ -9,6 +9,7 @@ import (
 
 var (
 	errMutualRecursionDetected = errors.New("mutual recursion detected")
+	errBindArgs                = errors.New("query has bind arguments, convert it with ToQueryArgs")
 )
 
 // Queryable represents an abstract entity that you can run query against.
@@ -18,7 +19,17 @@ type Queryable struct {
 	Joins      []Queryable
 	SelectOnly bool
 
-	where string
+	where     string
+	strategy  JoinStrategy
+	columns   []string
+	orderBy   []Order
+	limit     int
+	after     []interface{}
+	aggregate aggregation
+	required  bool
+	scope     Scope
+
+	excludeOwnBackend bool
 }
 
 // With appends "child" Queryables to the target Queryable which will be included
@@ -30,81 +41,176 @@ func (q Queryable) With(queryables ...Queryable) Queryable {
 }
 
 // Where specifies the sql WHERE clause for the queryable.
+// Columns can be qualified with the relation name (e.g. "pg_locks.granted"),
+// which is rewritten to the alias the relation gets in the generated query.
+// String literals and quoted identifiers in where are left as they are.
 func (q Queryable) Where(where string) Queryable {
 	_q := q
 	_q.where = where
 	return _q
 }
 
-// ToQuery converts the Queryable to a SQL query.
-func (q Queryable) ToQuery() (string, error) {
-	// Prevent infinite recursion
-	if q.mutualRecursionDetected() {
-		return "", errMutualRecursionDetected
-	}
+// Required keeps the rows of the parent queryable only when they have any
+// joined rows of q, matching its Where and its own required joins. It's
+// propagated to the parent as an EXISTS condition, so a required queryable
+// can also be filtered on without being selected, e.g. with Count.
+func (q Queryable) Required() Queryable {
+	_q := q
+	_q.required = true
+	return _q
+}
 
-	return q.toQuery()
+// ExcludeOwnBackend leaves out the rows of the backend running the query,
+// such as its own row in pg_stat_activity and its own locks in pg_locks,
+// at every level of the joins under q.
+func (q Queryable) ExcludeOwnBackend() Queryable {
+	_q := q
+	_q.excludeOwnBackend = true
+	return _q
 }
 
-func (q Queryable) getUnsupportedTargetError() error {
-	return fmt.Errorf("queryable %s is not supported in this version", q)
+// Columns restricts the columns selected for the queryable to the given ones.
+// Columns not given are left null in the scanned structs. Without Columns,
+// all the columns of the Specifier are selected.
+func (q Queryable) Columns(columns ...string) Queryable {
+	_q := q
+	_q.columns = columns
+	return _q
 }
 
-func (q Queryable) toQuery() (string, error) {
-	if q.Target == TargetUnspecified || (!q.SelectOnly && q.Specifier == nil) {
-		return "", q.getUnsupportedTargetError()
+// ScanDestinations returns the destinations of s for the columns selected for q,
+// followed by the destinations for the joined queryables.
+func (q Queryable) ScanDestinations(s Scannable) []interface{} {
+	dests := s.ScanDestinations(q.Joins)
+	if len(q.columns) == 0 || q.Specifier == nil {
+		return dests
 	}
 
-	var selects []string
-	if q.Specifier != nil {
-		selects = q.Specifier.Selects()
+	selected := q.selectedColumns()
+	all := q.Specifier.Selects()
+	projected := make([]interface{}, 0, len(dests))
+	for i, d := range dests {
+		if i >= len(all) || selected[all[i]] {
+			projected = append(projected, d)
+		}
 	}
-	var groupBys []string
-	if len(q.Joins) > 0 {
-		groupBys = selects
+	return projected
+}
+
+// selects returns the columns selected for q, in the order of its Specifier.
+func (q Queryable) selects() ([]string, error) {
+	if q.Specifier == nil {
+		return nil, nil
 	}
-	var where string
-	if q.where != "" {
-		where = fmt.Sprintf("WHERE %s", q.where)
+
+	all := q.Specifier.Selects()
+	if len(q.columns) == 0 {
+		return all, nil
 	}
 
-	joins := make([]string, 0, len(q.Joins))
-	for _, j := range q.Joins {
-		joinQuery, err := j.toQuery()
-		if err != nil {
-			return "", errors.Wrapf(err, "converting join query for %s under %s", j.Target, q.Target)
+	selected := q.selectedColumns()
+	selects := make([]string, 0, len(q.columns))
+	for _, c := range all {
+		if selected[c] {
+			selects = append(selects, c)
+			delete(selected, c)
 		}
-
-		columnFromJoin, joinAlias, joinCondition, err := q.Target.GetJoinClauses(j.Target)
-		if err != nil {
-			return "", errors.Wrap(err, "getting join clauses")
+	}
+	for _, c := range q.columns {
+		if selected[c] {
+			return nil, fmt.Errorf("column %s is not selectable from %s", c, q.Target)
 		}
+	}
+	return selects, nil
+}
 
-		selects = append(selects, columnFromJoin)
-		if !j.SelectOnly {
-			joins = append(joins, fmt.Sprintf(`LEFT JOIN (
-				%s
-			) AS %s ON %s`, joinQuery, joinAlias, joinCondition))
+// expression returns the SQL expression of a computed column of q.
+func (q Queryable) expression(column string) (string, bool) {
+	e, ok := q.Specifier.(ExpressionSelectable)
+	if !ok {
+		return "", false
+	}
+	expr, ok := e.Expressions()[column]
+	return expr, ok
+}
+
+// columnExpr returns the SQL expression of a column of q, whose relation is
+// aliased with alias.
+func (q Queryable) columnExpr(alias, column string) string {
+	if expr, ok := q.expression(column); ok {
+		return q.realias(expr, alias)
+	}
+	return fmt.Sprintf("%s.%s", alias, column)
+}
+
+func (q Queryable) columnExprs(alias string, columns []string) []string {
+	exprs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		exprs = append(exprs, q.columnExpr(alias, c))
+	}
+	return exprs
+}
+
+// selectClauses returns the select list items for the columns of q, naming
+// the computed ones after their column.
+func (q Queryable) selectClauses(alias string, columns []string) []string {
+	selects := q.columnExprs(alias, columns)
+	for i, c := range columns {
+		if _, ok := q.expression(c); ok {
+			selects[i] = fmt.Sprintf("%s AS %s", selects[i], c)
 		}
 	}
+	return selects
+}
 
-	var groupBy string
-	if len(groupBys) > 0 {
-		groupBy = fmt.Sprintf("GROUP BY %s", strings.Join(groupBys, ", "))
+func (q Queryable) selectedColumns() map[string]bool {
+	selected := make(map[string]bool, len(q.columns))
+	for _, c := range q.columns {
+		selected[c] = true
 	}
+	return selected
+}
 
-	return fmt.Sprintf(`SELECT
-		%s
-		FROM %s
-		%s
-		%s
-		%s`,
-		strings.Join(selects, ", "),
-		q.Target,
-		strings.Join(joins, ", "),
-		where,
-		groupBy,
-	), nil
+// JoinStrategy sets how the queryables joined under q are aggregated into its rows.
+// Joined queryables without a strategy of their own inherit it.
+func (q Queryable) JoinStrategy(strategy JoinStrategy) Queryable {
+	_q := q
+	_q.strategy = strategy
+	return _q
+}
+
+// ToQuery converts the Queryable to a SQL query. It fails if the query has
+// arguments to bind, such as the cursor values given to After.
+func (q Queryable) ToQuery() (string, error) {
+	sql, args, err := q.ToQueryArgs()
+	if err != nil {
+		return "", err
+	}
+	if len(args) > 0 {
+		return "", errBindArgs
+	}
+	return sql, nil
+}
+
+// ToQueryArgs converts the Queryable to a SQL query, along with the arguments
+// bound to its $n placeholders.
+func (q Queryable) ToQueryArgs() (string, []interface{}, error) {
+	// Prevent infinite recursion
+	if q.mutualRecursionDetected() {
+		return "", nil, errMutualRecursionDetected
+	}
+
+	return q.toQuery()
+}
+
+func (q Queryable) getUnsupportedTargetError() error {
+	return fmt.Errorf("queryable %s is not supported in this version", q)
+}
+
+func (q Queryable) toQuery() (string, []interface{}, error) {
+	b := builder{excludeOwnBackend: q.excludeOwnBackend}
+	sq, err := b.build(q, q.relation(), JoinStrategyDefault, nil, nil, false)
+	return sq.sql, b.args, err
 }
 
 // String is a string representation of Queryable
@@ -127,14 +233,19 @@ func (q Queryable) mutualRecursionDetected() bool {
 	return searchForSameQueryable(q, m)
 }
 
-func searchForSameQueryable(q Queryable, seen map[string]bool) bool {
-	if seen[q.String()] {
+// searchForSameQueryable reports whether q appears again under itself.
+// Only the ancestors are tracked, since the same queryable can appear
+// any number of times in different branches.
+func searchForSameQueryable(q Queryable, ancestors map[string]bool) bool {
+	s := q.String()
+	if ancestors[s] {
 		return true
 	}
-	seen[q.String()] = true
+	ancestors[s] = true
+	defer delete(ancestors, s)
 
 	for _, j := range q.Joins {
-		if searchForSameQueryable(j, seen) {
+		if searchForSameQueryable(j, ancestors) {
 			return true
 		}
 	}
@@ -0,0 +1,575 @@
+package query
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type columns []string
+
+func (c columns) Selects() []string {
+	return c
+}
+
+// slotColumns computes retained_bytes from restart_lsn.
+type slotColumns struct {
+	columns
+}
+
+func (c slotColumns) Expressions() map[string]string {
+	return map[string]string{
+		"retained_bytes": "pg_wal_lsn_diff(pg_current_wal_lsn(), pg_replication_slots.restart_lsn)",
+	}
+}
+
+var (
+	slots      = Queryable{Target: TargetReplicationSlots, Specifier: slotColumns{columns{"slot_name", "retained_bytes"}}}
+	tables     = Queryable{Target: TargetStatUserTables, Specifier: columns{"relid", "n_dead_tup"}}
+	indexes    = Queryable{Target: TargetStatUserIndexes, Specifier: columns{"indexrelid", "relid"}}
+	functions  = Queryable{Target: TargetStatUserFunctions, Specifier: columns{"funcid", "calls"}}
+	prepared   = Queryable{Target: TargetPreparedXacts, Specifier: columns{"transaction", "gid"}}
+	locks      = Queryable{Target: TargetLocks, Specifier: columns{"pid", "granted"}}
+	txLocks    = Queryable{Target: TargetLocksOnTxID, Specifier: columns{"pid", "transactionid"}}
+	activities = Queryable{Target: TargetStatActivity, Specifier: columns{"pid", "state"}}
+	blocking   = Queryable{Target: TargetBlockingPIDs, SelectOnly: true}
+	replays    = Queryable{Target: TargetStatReplication, Specifier: columns{"pid", "replay_lsn"}}
+	lagBytes   = Queryable{Target: TargetWALLSNDiff, SelectOnly: true}
+	databases  = Queryable{Target: TargetStatDatabase, Specifier: columns{"datid", "datname"}}
+	dbSize     = Queryable{Target: TargetDatabaseSize, SelectOnly: true}
+)
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+func normalize(q string) string {
+	q = whitespace.ReplaceAllString(q, " ")
+	q = strings.ReplaceAll(q, "( ", "(")
+	q = strings.ReplaceAll(q, " )", ")")
+	return strings.TrimSpace(q)
+}
+
+func TestQueryable_ToQuery(t *testing.T) {
+	cases := []struct {
+		description string
+		queryable   Queryable
+		expected    string
+		args        []interface{}
+	}{
+		{
+			description: "Top level relation should be aliased with its own name",
+			queryable:   locks.Where("pg_locks.granted"),
+			expected:    `SELECT pg_locks.pid, pg_locks.granted FROM pg_locks AS pg_locks WHERE pg_locks.granted`,
+		},
+		{
+			description: "Function targets should take arguments from the parent alias",
+			queryable:   activities.With(blocking),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, pg_blocking_pids(pg_stat_activity.pid) AS blocked_by ` +
+				`FROM pg_stat_activity AS pg_stat_activity`,
+		},
+		{
+			description: "Function target arguments other than columns should be passed as expressions",
+			queryable:   replays.With(lagBytes, locks),
+			expected: `SELECT pg_stat_replication.pid, pg_stat_replication.replay_lsn, ` +
+				`pg_wal_lsn_diff(CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END, pg_stat_replication.replay_lsn) AS replay_lag_bytes, ` +
+				`(CASE WHEN count(pg_locks_1) = 0 THEN '[]' ELSE json_agg(pg_locks_1) END) AS locks ` +
+				`FROM pg_stat_replication AS pg_stat_replication ` +
+				`LEFT JOIN (SELECT pg_locks_1.pid, pg_locks_1.granted FROM pg_locks AS pg_locks_1) AS pg_locks_1 ` +
+				`ON pg_locks_1.pid = pg_stat_replication.pid ` +
+				`GROUP BY pg_stat_replication.pid, pg_stat_replication.replay_lsn, ` +
+				`CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END`,
+		},
+		{
+			description: "Database size should be null for the shared objects rather than fail",
+			queryable:   databases.With(dbSize),
+			expected: `SELECT pg_stat_database.datid, pg_stat_database.datname, ` +
+				`pg_database_size(NULLIF(pg_stat_database.datid, 0::oid)) AS database_size ` +
+				`FROM pg_stat_database AS pg_stat_database`,
+		},
+		{
+			description: "Sibling joins on the same relation should get distinct aliases",
+			queryable:   activities.With(locks, txLocks),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, ` +
+				`(CASE WHEN count(pg_locks_1) = 0 THEN '[]' ELSE json_agg(pg_locks_1) END) AS locks, ` +
+				`(CASE WHEN count(pg_locks_2) = 0 THEN '[]' ELSE json_agg(pg_locks_2) END) AS tx_locks ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_1.pid, pg_locks_1.granted FROM pg_locks AS pg_locks_1) AS pg_locks_1 ` +
+				`ON pg_locks_1.pid = pg_stat_activity.pid ` +
+				`LEFT JOIN (SELECT pg_locks_2.pid, pg_locks_2.transactionid FROM pg_locks AS pg_locks_2) AS pg_locks_2 ` +
+				`ON pg_locks_2.transactionid = pg_stat_activity.backend_xid ` +
+				`GROUP BY pg_stat_activity.pid, pg_stat_activity.state`,
+		},
+		{
+			description: "Nested joins on the same relation as an ancestor should not be ambiguous",
+			queryable: activities.With(
+				locks.Where("NOT pg_locks.granted").With(
+					activities.Where("pg_stat_activity.state = 'active'").With(blocking),
+				),
+			),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, ` +
+				`(CASE WHEN count(pg_locks_1) = 0 THEN '[]' ELSE json_agg(pg_locks_1) END) AS locks ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_1.pid, pg_locks_1.granted, ` +
+				`(CASE WHEN count(pg_stat_activity_2) = 0 THEN '[]' ELSE json_agg(pg_stat_activity_2) END) AS activities ` +
+				`FROM pg_locks AS pg_locks_1 ` +
+				`LEFT JOIN (SELECT pg_stat_activity_2.pid, pg_stat_activity_2.state, ` +
+				`pg_blocking_pids(pg_stat_activity_2.pid) AS blocked_by ` +
+				`FROM pg_stat_activity AS pg_stat_activity_2 ` +
+				`WHERE pg_stat_activity_2.state = 'active') AS pg_stat_activity_2 ` +
+				`ON pg_stat_activity_2.pid = pg_locks_1.pid ` +
+				`WHERE NOT pg_locks_1.granted ` +
+				`GROUP BY pg_locks_1.pid, pg_locks_1.granted) AS pg_locks_1 ` +
+				`ON pg_locks_1.pid = pg_stat_activity.pid ` +
+				`GROUP BY pg_stat_activity.pid, pg_stat_activity.state`,
+		},
+		{
+			description: "Lateral strategy should aggregate the correlated rows without grouping",
+			queryable: activities.JoinStrategy(JoinStrategyLateral).With(
+				locks.Where("NOT pg_locks.granted").With(activities),
+				blocking,
+			),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, pg_locks_1_agg.locks, ` +
+				`pg_blocking_pids(pg_stat_activity.pid) AS blocked_by ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN LATERAL (SELECT COALESCE(json_agg(pg_locks_1), '[]') AS locks FROM (` +
+				`SELECT pg_locks_1.pid, pg_locks_1.granted, pg_stat_activity_2_agg.activities ` +
+				`FROM pg_locks AS pg_locks_1 ` +
+				`LEFT JOIN LATERAL (SELECT COALESCE(json_agg(pg_stat_activity_2), '[]') AS activities FROM (` +
+				`SELECT pg_stat_activity_2.pid, pg_stat_activity_2.state ` +
+				`FROM pg_stat_activity AS pg_stat_activity_2 ` +
+				`WHERE pg_stat_activity_2.pid = pg_locks_1.pid` +
+				`) AS pg_stat_activity_2) AS pg_stat_activity_2_agg ON true ` +
+				`WHERE (NOT pg_locks_1.granted) AND (pg_locks_1.pid = pg_stat_activity.pid)` +
+				`) AS pg_locks_1) AS pg_locks_1_agg ON true`,
+		},
+		{
+			description: "Join strategy set on a child should apply under the child only",
+			queryable:   activities.With(locks.JoinStrategy(JoinStrategyLateral).With(activities)),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, ` +
+				`(CASE WHEN count(pg_locks_1) = 0 THEN '[]' ELSE json_agg(pg_locks_1) END) AS locks ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_1.pid, pg_locks_1.granted, pg_stat_activity_2_agg.activities ` +
+				`FROM pg_locks AS pg_locks_1 ` +
+				`LEFT JOIN LATERAL (SELECT COALESCE(json_agg(pg_stat_activity_2), '[]') AS activities FROM (` +
+				`SELECT pg_stat_activity_2.pid, pg_stat_activity_2.state ` +
+				`FROM pg_stat_activity AS pg_stat_activity_2 ` +
+				`WHERE pg_stat_activity_2.pid = pg_locks_1.pid` +
+				`) AS pg_stat_activity_2) AS pg_stat_activity_2_agg ON true` +
+				`) AS pg_locks_1 ON pg_locks_1.pid = pg_stat_activity.pid ` +
+				`GROUP BY pg_stat_activity.pid, pg_stat_activity.state`,
+		},
+		{
+			description: "Columns should restrict the projection at every level, keeping the join columns out of the rows",
+			queryable:   activities.Columns("state").With(locks.Columns("granted")),
+			expected: `SELECT pg_stat_activity.state, ` +
+				`(CASE WHEN count(pg_locks_1) = 0 THEN '[]' ELSE ` +
+				`json_agg((SELECT pg_locks_1_row FROM (SELECT pg_locks_1.granted) AS pg_locks_1_row)) END) AS locks ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_1.granted, pg_locks_1.pid FROM pg_locks AS pg_locks_1) AS pg_locks_1 ` +
+				`ON pg_locks_1.pid = pg_stat_activity.pid ` +
+				`GROUP BY pg_stat_activity.state, pg_stat_activity.pid`,
+		},
+		{
+			description: "Projected rows should be grouped by their key at every level, or else by all their columns",
+			queryable:   databases.Columns("datname").With(activities.Columns("state").With(txLocks.Columns("pid"))),
+			expected: `SELECT pg_stat_database.datname, ` +
+				`(CASE WHEN count(pg_stat_activity_1) = 0 THEN '[]' ELSE ` +
+				`json_agg((SELECT pg_stat_activity_1_row FROM (SELECT pg_stat_activity_1.state, pg_stat_activity_1.tx_locks) AS pg_stat_activity_1_row)) END) AS activities ` +
+				`FROM pg_stat_database AS pg_stat_database ` +
+				`LEFT JOIN (SELECT pg_stat_activity_1.state, pg_stat_activity_1.datid, ` +
+				`(CASE WHEN count(pg_locks_2) = 0 THEN '[]' ELSE ` +
+				`json_agg((SELECT pg_locks_2_row FROM (SELECT pg_locks_2.pid) AS pg_locks_2_row)) END) AS tx_locks ` +
+				`FROM pg_stat_activity AS pg_stat_activity_1 ` +
+				`LEFT JOIN (SELECT pg_locks_2.pid, pg_locks_2.transactionid FROM pg_locks AS pg_locks_2) AS pg_locks_2 ` +
+				`ON pg_locks_2.transactionid = pg_stat_activity_1.backend_xid ` +
+				`GROUP BY pg_stat_activity_1.state, pg_stat_activity_1.datid, pg_stat_activity_1.pid) AS pg_stat_activity_1 ` +
+				`ON pg_stat_activity_1.datid = pg_stat_database.datid ` +
+				`GROUP BY pg_stat_database.datname, pg_stat_database.datid`,
+		},
+		{
+			description: "Top level rows should be ordered and limited after the cursor",
+			queryable: activities.
+				OrderBy(Desc("state"), Asc("pid")).
+				After("idle", 42).
+				Limit(20),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`WHERE (pg_stat_activity.state < $1) OR ` +
+				`(pg_stat_activity.state = $1 AND pg_stat_activity.pid > $2) ` +
+				`ORDER BY pg_stat_activity.state DESC, pg_stat_activity.pid ASC LIMIT 20`,
+			args: []interface{}{"idle", 42},
+		},
+		{
+			description: "Cursors at every level should be bound in the order they appear",
+			queryable: activities.OrderBy(Asc("pid")).After(42).With(
+				locks.OrderBy(Asc("granted")).After(true),
+			),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, pg_locks_1_agg.locks ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN LATERAL (SELECT COALESCE(json_agg(pg_locks_1), '[]') AS locks FROM (` +
+				`SELECT pg_locks_1.pid, pg_locks_1.granted FROM pg_locks AS pg_locks_1 ` +
+				`WHERE (pg_locks_1.pid = pg_stat_activity.pid) AND ((pg_locks_1.granted > $2)) ` +
+				`ORDER BY pg_locks_1.granted ASC) AS pg_locks_1) AS pg_locks_1_agg ON true ` +
+				`WHERE (pg_stat_activity.pid > $1) ` +
+				`ORDER BY pg_stat_activity.pid ASC`,
+			args: []interface{}{42, true},
+		},
+		{
+			description: "Joined rows should be limited per parent row, grouping by the correlated columns",
+			queryable: activities.Columns("state").OrderBy(Asc("pid")).With(
+				locks.OrderBy(Asc("granted")).Limit(10),
+				txLocks,
+			),
+			expected: `SELECT pg_stat_activity.state, ` +
+				`(SELECT COALESCE(json_agg(pg_locks_1), '[]') AS locks FROM (` +
+				`SELECT pg_locks_1.pid, pg_locks_1.granted FROM pg_locks AS pg_locks_1 ` +
+				`WHERE pg_locks_1.pid = pg_stat_activity.pid ` +
+				`ORDER BY pg_locks_1.granted ASC LIMIT 10) AS pg_locks_1) AS locks, ` +
+				`(CASE WHEN count(pg_locks_2) = 0 THEN '[]' ELSE json_agg(pg_locks_2) END) AS tx_locks ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_2.pid, pg_locks_2.transactionid FROM pg_locks AS pg_locks_2) AS pg_locks_2 ` +
+				`ON pg_locks_2.transactionid = pg_stat_activity.backend_xid ` +
+				`GROUP BY pg_stat_activity.state, pg_stat_activity.pid ` +
+				`ORDER BY pg_stat_activity.pid ASC`,
+		},
+		{
+			description: "Count should aggregate the joined rows into a scalar column",
+			queryable:   activities.With(locks.Count()),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, ` +
+				`(count(pg_locks_1)) AS locks_count ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_1.pid, pg_locks_1.granted FROM pg_locks AS pg_locks_1) AS pg_locks_1 ` +
+				`ON pg_locks_1.pid = pg_stat_activity.pid ` +
+				`GROUP BY pg_stat_activity.pid, pg_stat_activity.state`,
+		},
+		{
+			description: "Aggregated column should be selected even if not projected, and nested aggregates keyed by kind",
+			queryable:   activities.With(locks.Columns("granted").With(activities.Exists()).ArrayAgg("pid")),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, ` +
+				`(COALESCE(array_remove(array_agg(pg_locks_1.pid::text), NULL), '{}')) AS locks_array ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_1.granted, pg_locks_1.pid, ` +
+				`(json_build_object('exists', count(pg_stat_activity_2) > 0)) AS activities_aggregate ` +
+				`FROM pg_locks AS pg_locks_1 ` +
+				`LEFT JOIN (SELECT pg_stat_activity_2.pid, pg_stat_activity_2.state ` +
+				`FROM pg_stat_activity AS pg_stat_activity_2) AS pg_stat_activity_2 ` +
+				`ON pg_stat_activity_2.pid = pg_locks_1.pid ` +
+				`GROUP BY pg_locks_1.granted, pg_locks_1.pid) AS pg_locks_1 ` +
+				`ON pg_locks_1.pid = pg_stat_activity.pid ` +
+				`GROUP BY pg_stat_activity.pid, pg_stat_activity.state`,
+		},
+		{
+			description: "Lateral strategy should aggregate the correlated rows into a scalar column",
+			queryable:   activities.JoinStrategy(JoinStrategyLateral).With(locks.Sum("pid")),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, pg_locks_1_agg.locks_sum ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN LATERAL (SELECT COALESCE(sum(pg_locks_1.pid), 0) AS locks_sum FROM (` +
+				`SELECT pg_locks_1.pid, pg_locks_1.granted FROM pg_locks AS pg_locks_1 ` +
+				`WHERE pg_locks_1.pid = pg_stat_activity.pid` +
+				`) AS pg_locks_1) AS pg_locks_1_agg ON true`,
+		},
+		{
+			description: "Required joins should filter the parent rows recursively",
+			queryable: activities.With(
+				locks.Where("NOT pg_locks.granted").Required().With(
+					activities.Where("pg_stat_activity.state = 'active'").Count().Required(),
+				),
+			),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, ` +
+				`(CASE WHEN count(pg_locks_3) = 0 THEN '[]' ELSE json_agg(pg_locks_3) END) AS locks ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_3.pid, pg_locks_3.granted, ` +
+				`(json_build_object('count', count(pg_stat_activity_5))) AS activities_aggregate ` +
+				`FROM pg_locks AS pg_locks_3 ` +
+				`LEFT JOIN (SELECT pg_stat_activity_5.pid, pg_stat_activity_5.state ` +
+				`FROM pg_stat_activity AS pg_stat_activity_5 ` +
+				`WHERE pg_stat_activity_5.state = 'active') AS pg_stat_activity_5 ` +
+				`ON pg_stat_activity_5.pid = pg_locks_3.pid ` +
+				`WHERE (NOT pg_locks_3.granted) AND (EXISTS (SELECT 1 FROM pg_stat_activity AS pg_stat_activity_4 ` +
+				`WHERE (pg_stat_activity_4.pid = pg_locks_3.pid) AND (pg_stat_activity_4.state = 'active'))) ` +
+				`GROUP BY pg_locks_3.pid, pg_locks_3.granted) AS pg_locks_3 ` +
+				`ON pg_locks_3.pid = pg_stat_activity.pid ` +
+				`WHERE EXISTS (SELECT 1 FROM pg_locks AS pg_locks_1 ` +
+				`WHERE (pg_locks_1.pid = pg_stat_activity.pid) AND (NOT pg_locks_1.granted) AND ` +
+				`(EXISTS (SELECT 1 FROM pg_stat_activity AS pg_stat_activity_2 ` +
+				`WHERE (pg_stat_activity_2.pid = pg_locks_1.pid) AND (pg_stat_activity_2.state = 'active')))) ` +
+				`GROUP BY pg_stat_activity.pid, pg_stat_activity.state`,
+		},
+		{
+			description: "Own backend should be left out at every level of the joins",
+			queryable:   activities.With(locks, blocking).ExcludeOwnBackend(),
+			expected: `SELECT pg_stat_activity.pid, pg_stat_activity.state, ` +
+				`(CASE WHEN count(pg_locks_1) = 0 THEN '[]' ELSE json_agg(pg_locks_1) END) AS locks, ` +
+				`pg_blocking_pids(pg_stat_activity.pid) AS blocked_by ` +
+				`FROM pg_stat_activity AS pg_stat_activity ` +
+				`LEFT JOIN (SELECT pg_locks_1.pid, pg_locks_1.granted FROM pg_locks AS pg_locks_1 ` +
+				`WHERE pg_locks_1.pid IS DISTINCT FROM pg_backend_pid()) AS pg_locks_1 ` +
+				`ON pg_locks_1.pid = pg_stat_activity.pid ` +
+				`WHERE pg_stat_activity.pid IS DISTINCT FROM pg_backend_pid() ` +
+				`GROUP BY pg_stat_activity.pid, pg_stat_activity.state`,
+		},
+		{
+			description: "Computed columns should be selected, grouped and ordered by their expression",
+			queryable:   slots.OrderBy(Desc("retained_bytes")).With(activities),
+			expected: `SELECT pg_replication_slots.slot_name, ` +
+				`pg_wal_lsn_diff(pg_current_wal_lsn(), pg_replication_slots.restart_lsn) AS retained_bytes, ` +
+				`(CASE WHEN count(pg_stat_activity_1) = 0 THEN '[]' ELSE json_agg(pg_stat_activity_1) END) AS activities ` +
+				`FROM pg_replication_slots AS pg_replication_slots ` +
+				`LEFT JOIN (SELECT pg_stat_activity_1.pid, pg_stat_activity_1.state FROM pg_stat_activity AS pg_stat_activity_1) AS pg_stat_activity_1 ` +
+				`ON pg_stat_activity_1.pid = pg_replication_slots.active_pid ` +
+				`GROUP BY pg_replication_slots.slot_name, ` +
+				`pg_wal_lsn_diff(pg_current_wal_lsn(), pg_replication_slots.restart_lsn) ` +
+				`ORDER BY pg_wal_lsn_diff(pg_current_wal_lsn(), pg_replication_slots.restart_lsn) DESC`,
+		},
+		{
+			description: "Join conditions should be rendered from their templates",
+			queryable:   prepared.With(locks.Count()),
+			expected: `SELECT pg_prepared_xacts.transaction, pg_prepared_xacts.gid, ` +
+				`(count(pg_locks_1)) AS locks_count ` +
+				`FROM pg_prepared_xacts AS pg_prepared_xacts ` +
+				`LEFT JOIN (SELECT pg_locks_1.pid, pg_locks_1.granted, pg_locks_1.virtualtransaction FROM pg_locks AS pg_locks_1) AS pg_locks_1 ` +
+				`ON pg_locks_1.virtualtransaction = '-1/' || pg_prepared_xacts.transaction ` +
+				`GROUP BY pg_prepared_xacts.transaction, pg_prepared_xacts.gid`,
+		},
+		{
+			description: "Scoped views should read their variant, with columns qualified with either name realiased",
+			queryable: tables.Scope(ScopeAll).Where("pg_stat_user_tables.n_dead_tup > 0").With(
+				indexes.Scope(ScopeSys).Where("pg_stat_sys_indexes.indexrelid > 0").Required(),
+			),
+			expected: `SELECT pg_stat_all_tables.relid, pg_stat_all_tables.n_dead_tup, ` +
+				`(CASE WHEN count(pg_stat_sys_indexes_2) = 0 THEN '[]' ELSE json_agg(pg_stat_sys_indexes_2) END) AS indexes ` +
+				`FROM pg_stat_all_tables AS pg_stat_all_tables ` +
+				`LEFT JOIN (SELECT pg_stat_sys_indexes_2.indexrelid, pg_stat_sys_indexes_2.relid FROM pg_stat_sys_indexes AS pg_stat_sys_indexes_2 ` +
+				`WHERE pg_stat_sys_indexes_2.indexrelid > 0) AS pg_stat_sys_indexes_2 ON pg_stat_sys_indexes_2.relid = pg_stat_all_tables.relid ` +
+				`WHERE (pg_stat_all_tables.n_dead_tup > 0) AND ` +
+				`(EXISTS (SELECT 1 FROM pg_stat_sys_indexes AS pg_stat_sys_indexes_1 ` +
+				`WHERE (pg_stat_sys_indexes_1.relid = pg_stat_all_tables.relid) AND (pg_stat_sys_indexes_1.indexrelid > 0))) ` +
+				`GROUP BY pg_stat_all_tables.relid, pg_stat_all_tables.n_dead_tup`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			q, args, err := c.queryable.ToQueryArgs()
+			if err != nil {
+				t.Fatalf("Expected nil error but got %v", err)
+			}
+			if got := normalize(q); got != c.expected {
+				t.Errorf("Expected query\n%s\nbut got\n%s", c.expected, got)
+			}
+			if len(args) > 0 || len(c.args) > 0 {
+				if !reflect.DeepEqual(args, c.args) {
+					t.Errorf("Expected args %v but got %v", c.args, args)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryable_ToQuery_UnsupportedJoin(t *testing.T) {
+	_, err := locks.With(txLocks).ToQuery()
+	if err == nil {
+		t.Error("Expected error for unsupported join but got nil")
+	}
+}
+
+func TestQueryable_ToQuery_UnknownColumn(t *testing.T) {
+	_, err := activities.With(locks.Columns("pid", "query")).ToQuery()
+	if err == nil {
+		t.Error("Expected error for unknown column but got nil")
+	}
+}
+
+type activityRow struct {
+	pid, state, locks string
+}
+
+func (r *activityRow) ScanDestinations(joins []Queryable) []interface{} {
+	dests := []interface{}{&r.pid, &r.state}
+	for range joins {
+		dests = append(dests, &r.locks)
+	}
+	return dests
+}
+
+func TestQueryable_ScanDestinations(t *testing.T) {
+	var r activityRow
+	dests := activities.Columns("state").With(locks).ScanDestinations(&r)
+	if len(dests) != 2 || dests[0] != &r.state || dests[1] != &r.locks {
+		t.Errorf("Expected destinations of state and locks but got %v", dests)
+	}
+}
+
+func TestQueryable_ToQuery_InvalidModifiers(t *testing.T) {
+	cases := []struct {
+		description string
+		queryable   Queryable
+	}{
+		{
+			description: "Ordering by an unknown column should fail",
+			queryable:   activities.With(locks.OrderBy(Asc("query"))),
+		},
+		{
+			description: "Aggregating an unknown column should fail",
+			queryable:   activities.With(locks.Sum("query")),
+		},
+		{
+			description: "Requiring a select-only queryable should fail",
+			queryable:   activities.With(blocking.Required()),
+		},
+		{
+			description: "Null cursor values should fail",
+			queryable:   activities.OrderBy(Asc("pid")).After(nil),
+		},
+		{
+			description: "Converting a query with bind arguments without them should fail",
+			queryable:   activities.OrderBy(Asc("pid")).After(42),
+		},
+		{
+			description: "Cursor not matching the ordered columns should fail",
+			queryable:   activities.OrderBy(Asc("pid")).After(1, "active"),
+		},
+		{
+			description: "Scoping a view with no such variant should fail",
+			queryable:   functions.Scope(ScopeAll),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := c.queryable.ToQuery(); err == nil {
+				t.Error("Expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestToSnapshotQuery(t *testing.T) {
+	q, args, err := ToSnapshotQuery([]Queryable{
+		activities.Columns("state"),
+		locks.Where("NOT pg_locks.granted").With(activities.Count()),
+	})
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	expected := `SELECT now() AS captured_at, ` +
+		`(SELECT COALESCE(json_agg(pg_stat_activity_1), '[]') FROM (` +
+		`SELECT pg_stat_activity.state FROM pg_stat_activity AS pg_stat_activity` +
+		`) AS pg_stat_activity_1) AS pg_stat_activity_1, ` +
+		`(SELECT COALESCE(json_agg(pg_locks_2), '[]') FROM (` +
+		`SELECT pg_locks.pid, pg_locks.granted, ` +
+		`(json_build_object('count', count(pg_stat_activity_3))) AS activities_aggregate ` +
+		`FROM pg_locks AS pg_locks ` +
+		`LEFT JOIN (SELECT pg_stat_activity_3.pid, pg_stat_activity_3.state ` +
+		`FROM pg_stat_activity AS pg_stat_activity_3) AS pg_stat_activity_3 ` +
+		`ON pg_stat_activity_3.pid = pg_locks.pid ` +
+		`WHERE NOT pg_locks.granted ` +
+		`GROUP BY pg_locks.pid, pg_locks.granted` +
+		`) AS pg_locks_2) AS pg_locks_2`
+	if got := normalize(q); got != expected {
+		t.Errorf("Expected query\n%s\nbut got\n%s", expected, got)
+	}
+	if len(args) != 0 {
+		t.Errorf("Expected no args but got %v", args)
+	}
+}
+
+func TestToSnapshotQuery_DuplicateTarget(t *testing.T) {
+	_, _, err := ToSnapshotQuery([]Queryable{activities, activities.Where("pg_stat_activity.state = 'active'")})
+	if err == nil {
+		t.Error("Expected error for duplicate target but got nil")
+	}
+}
+
+func TestQueryable_Describe(t *testing.T) {
+	q := activities.Where("state = 'active'").OrderBy(Desc("pid")).Limit(5).With(
+		locks.Count().Required(),
+		blocking,
+	)
+
+	expected := `pg_stat_activity(where: state = 'active', order: pid desc, limit: 5)[pg_locks(count, required), pg_blocking_pids]`
+	if got := q.Describe(); got != expected {
+		t.Errorf("Expected description\n%s\nbut got\n%s", expected, got)
+	}
+}
+
+func TestQueryable_Compile(t *testing.T) {
+	q := activities.Columns("state").With(locks)
+	c, err := q.Compile()
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	sql, _ := q.ToQuery()
+	if c.SQL() != sql {
+		t.Errorf("Expected compiled query\n%s\nbut got\n%s", sql, c.SQL())
+	}
+
+	var r activityRow
+	dests := c.ScanDestinations(&r)
+	if len(dests) != 2 || dests[0] != &r.state || dests[1] != &r.locks {
+		t.Errorf("Expected destinations of state and locks but got %v", dests)
+	}
+}
+
+func TestQueryable_Layout(t *testing.T) {
+	l, err := activities.Columns("state").With(locks.With(activities.Count()), txLocks.Exists(), blocking).Layout()
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	expected := "state locks tx_locks_exists blocked_by"
+	if got := strings.Join(l.Columns, " "); got != expected {
+		t.Errorf("Expected columns %s but got %s", expected, got)
+	}
+	if len(l.Joins) != 1 {
+		t.Fatalf("Expected layout of locks only but got %v", l.Joins)
+	}
+	expected = "pid granted activities_aggregate"
+	if got := strings.Join(l.Joins["locks"].Columns, " "); got != expected {
+		t.Errorf("Expected columns %s but got %s", expected, got)
+	}
+}
+
+func TestRealias(t *testing.T) {
+	cases := []struct {
+		description string
+		clause      string
+		expected    string
+	}{
+		{
+			description: "Qualified columns should be realiased",
+			clause:      "pg_locks.granted AND NOT pg_locks.fastpath",
+			expected:    "pg_locks_1.granted AND NOT pg_locks_1.fastpath",
+		},
+		{
+			description: "Other relations and suffixed names should be left as they are",
+			clause:      "my_pg_locks.pid = pg_locks.pid AND s.pg_locks.pid > 0",
+			expected:    "my_pg_locks.pid = pg_locks_1.pid AND s.pg_locks.pid > 0",
+		},
+		{
+			description: "String literals should be left as they are",
+			clause:      "pg_locks.mode <> 'pg_locks.mode' AND pg_locks.mode <> 'it''s pg_locks.mode'",
+			expected:    "pg_locks_1.mode <> 'pg_locks.mode' AND pg_locks_1.mode <> 'it''s pg_locks.mode'",
+		},
+		{
+			description: "Escape strings should end at their unescaped quote",
+			clause:      `E'\' pg_locks.mode' = pg_locks.mode`,
+			expected:    `E'\' pg_locks.mode' = pg_locks_1.mode`,
+		},
+		{
+			description: "Quoted identifiers and dollar quoted strings should be left as they are",
+			clause:      `"pg_locks.mode" = $q$pg_locks.mode$q$ AND pg_locks.pid = $1`,
+			expected:    `"pg_locks.mode" = $q$pg_locks.mode$q$ AND pg_locks_1.pid = $1`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if got := realias(c.clause, "pg_locks", "pg_locks_1"); got != c.expected {
+				t.Errorf("Expected %s but got %s", c.expected, got)
+			}
+		})
+	}
+}
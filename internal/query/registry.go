@@ -0,0 +1,274 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errEmptyRelation = errors.New("target definition has no relation")
+	errEmptyColumn   = errors.New("join definition has no column")
+	errEmptyOn       = errors.New("join definition has no columns to join on")
+	errEmptyArgs     = errors.New("join definition has no function arguments")
+)
+
+// TargetDefinition declares a queryable target: the relation it reads from,
+// the columns identifying its rows, and the targets that can be joined under it.
+type TargetDefinition struct {
+	// Relation is the name of the relation (table, view) the target reads from.
+	// For function targets, it's the name of the function.
+	Relation string
+
+	// KeyColumns are the columns that identify a row of the relation, if any.
+	// The rows are grouped by them when they're aggregating joined rows, so
+	// that the rows of a projection without them aren't merged. Without key
+	// columns, they're grouped by all the columns of their Selectable.
+	KeyColumns []string
+
+	// Joins lists the targets that can be joined under this target.
+	Joins []Join
+
+	// Function reports whether the target is a function call rather than a relation.
+	// Function targets can only be joined as select-only queryables.
+	Function bool
+
+	// ScopedRelations maps the scopes the target can be read in, other than
+	// ScopeUser, to the relations it reads in them.
+	ScopedRelations map[Scope]string
+
+	// BackendPIDColumn is the column holding the PID of a backend, if any.
+	// The rows of the backend running the query are left out on this column
+	// for queryables set with ExcludeOwnBackend.
+	BackendPIDColumn string
+
+	// scannable is the struct type the rows of a custom target are scanned
+	// into, if one was registered with it.
+	scannable reflect.Type
+}
+
+// Join declares how a target is joined under its parent target.
+type Join struct {
+	// Target is the target being joined.
+	Target Target
+
+	// Column is the name of the column the joined rows are aggregated into.
+	Column string
+
+	// On lists the pairs of columns the parent and the joined target are joined on.
+	On []JoinOn
+
+	// Args lists the parent columns passed as arguments when the joined target is a function.
+	// Arguments other than plain column names are passed as SQL expressions, in
+	// which {parent} is replaced with the parent alias, e.g.
+	//  pg_current_wal_lsn()
+	//  {parent}.replay_lsn
+	Args []string
+}
+
+// JoinOn is a pair of columns compared for equality in a join condition.
+type JoinOn struct {
+	Parent string
+	Child  string
+
+	// Condition compares the columns other than for equality, if set.
+	// {parent} and {child} are replaced with the qualified columns, e.g.
+	//  {child} = '-1/' || {parent}
+	Condition string
+}
+
+func (on JoinOn) condition(parentAlias, alias string) string {
+	condition := on.Condition
+	if condition == "" {
+		condition = "{child} = {parent}"
+	}
+	return strings.NewReplacer(
+		"{parent}", fmt.Sprintf("%s.%s", parentAlias, on.Parent),
+		"{child}", fmt.Sprintf("%s.%s", alias, on.Child),
+	).Replace(condition)
+}
+
+var columnName = regexp.MustCompile(`^\w+$`)
+
+func (j Join) functionClause(fn, parentAlias string) string {
+	return fmt.Sprintf("%s(%s) AS %s", fn, strings.Join(j.argExprs(parentAlias), ", "), j.Column)
+}
+
+// argExprs returns the SQL expressions of the function arguments.
+func (j Join) argExprs(parentAlias string) []string {
+	args := make([]string, 0, len(j.Args))
+	for _, a := range j.Args {
+		if columnName.MatchString(a) {
+			args = append(args, fmt.Sprintf("%s.%s", parentAlias, a))
+		} else {
+			args = append(args, strings.ReplaceAll(a, "{parent}", parentAlias))
+		}
+	}
+	return args
+}
+
+func (j Join) parentColumns() []string {
+	columns := make([]string, 0, len(j.On))
+	for _, on := range j.On {
+		columns = append(columns, on.Parent)
+	}
+	return columns
+}
+
+func (j Join) childColumns() []string {
+	columns := make([]string, 0, len(j.On))
+	for _, on := range j.On {
+		columns = append(columns, on.Child)
+	}
+	return columns
+}
+
+func (j Join) condition(parentAlias, alias string) string {
+	conds := make([]string, 0, len(j.On))
+	for _, on := range j.On {
+		conds = append(conds, on.condition(parentAlias, alias))
+	}
+	return strings.Join(conds, " AND ")
+}
+
+var registry struct {
+	sync.RWMutex
+	targets []*TargetDefinition
+}
+
+// RegisterTarget adds a new target to the registry and returns it.
+// Once registered, the target can be queried and joined like the built-in ones.
+// The scannable, if not nil, is a pointer to the struct the rows of the target
+// are scanned into, new values of which are returned by Queryable.NewScannable.
+func RegisterTarget(def TargetDefinition, scannable Scannable) (Target, error) {
+	if def.Relation == "" {
+		return TargetUnspecified, errEmptyRelation
+	}
+	for _, j := range def.Joins {
+		if err := validateJoin(j); err != nil {
+			return TargetUnspecified, errors.Wrapf(err, "validating join to %s", j.Target)
+		}
+	}
+	if scannable != nil {
+		t := reflect.TypeOf(scannable)
+		if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			return TargetUnspecified, fmt.Errorf("scannable %T is not a pointer to a struct", scannable)
+		}
+		def.scannable = t.Elem()
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.targets = append(registry.targets, &def)
+	return Target(len(registry.targets)), nil
+}
+
+// RegisterJoin declares an additional join under an already registered target.
+// It's useful for joining custom targets under the built-in ones.
+func RegisterJoin(from Target, j Join) error {
+	if err := validateJoin(j); err != nil {
+		return err
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	def, ok := lookupTargetLocked(from)
+	if !ok {
+		return fmt.Errorf("target %d is not registered", from)
+	}
+	for _, existing := range def.Joins {
+		if existing.Target == j.Target {
+			return joiner{from: from, join: j.Target}.GetAlreadyRegisteredJoinError()
+		}
+	}
+
+	def.Joins = append(def.Joins, j)
+	return nil
+}
+
+func validateJoin(j Join) error {
+	if j.Column == "" {
+		return errEmptyColumn
+	}
+	def, ok := lookupTarget(j.Target)
+	if !ok {
+		return fmt.Errorf("target %d is not registered", j.Target)
+	}
+	if def.Function && len(j.Args) == 0 {
+		return errEmptyArgs
+	}
+	if !def.Function && len(j.On) == 0 {
+		return errEmptyOn
+	}
+	return nil
+}
+
+// NewScannable returns a new value of the struct registered with the custom
+// target of q to scan its rows into, if any.
+func (q Queryable) NewScannable() (Scannable, bool) {
+	def, ok := lookupTarget(q.Target)
+	if !ok || def.scannable == nil {
+		return nil, false
+	}
+	return reflect.New(def.scannable).Interface().(Scannable), true
+}
+
+// SaveRegistry returns a function restoring the targets and the joins of the
+// registry as they are now, for tests registering their own.
+func SaveRegistry() (restore func()) {
+	registry.RLock()
+	saved := make([]TargetDefinition, 0, len(registry.targets))
+	for _, def := range registry.targets {
+		d := *def
+		d.Joins = append([]Join{}, def.Joins...)
+		saved = append(saved, d)
+	}
+	registry.RUnlock()
+
+	return func() {
+		registry.Lock()
+		defer registry.Unlock()
+
+		registry.targets = registry.targets[:0]
+		for i := range saved {
+			def := saved[i]
+			registry.targets = append(registry.targets, &def)
+		}
+	}
+}
+
+func lookupTarget(t Target) (TargetDefinition, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	def, ok := lookupTargetLocked(t)
+	if !ok {
+		return TargetDefinition{}, false
+	}
+	return *def, true
+}
+
+func lookupTargetLocked(t Target) (*TargetDefinition, bool) {
+	if t <= TargetUnspecified || int(t) > len(registry.targets) {
+		return nil, false
+	}
+	return registry.targets[t-1], true
+}
+
+func lookupJoin(from, join Target) (Join, error) {
+	def, ok := lookupTarget(from)
+	if ok {
+		for _, j := range def.Joins {
+			if j.Target == join {
+				return j, nil
+			}
+		}
+	}
+	return Join{}, joiner{from: from, join: join}.GetUnsupportedJoinError()
+}
@@ -0,0 +1,77 @@
+package query
+
+import "fmt"
+
+// Scope selects the objects covered by a statistics view: the user objects,
+// the system objects, i.e. those in pg_catalog, information_schema and the
+// toast schemas, or both.
+type Scope int
+
+// Scopes defined
+const (
+	// ScopeUser covers the user objects, as pg_stat_user_tables. It's the default.
+	ScopeUser Scope = iota
+
+	// ScopeSys covers the system objects, as pg_stat_sys_tables.
+	ScopeSys
+
+	// ScopeAll covers all the objects, as pg_stat_all_tables.
+	ScopeAll
+)
+
+// String returns the stringified scope.
+func (s Scope) String() string {
+	switch s {
+	case ScopeUser:
+		return "user"
+	case ScopeSys:
+		return "sys"
+	case ScopeAll:
+		return "all"
+	}
+	return fmt.Sprintf("scope(%d)", int(s))
+}
+
+// Scope sets the objects covered by the view q reads, e.g. pg_stat_all_tables
+// rather than pg_stat_user_tables for ScopeAll. The joins and the rows of the
+// view are the same in every scope. Targets with no such variant of their
+// relation, such as pg_stat_user_functions, fail to convert to a query.
+func (q Queryable) Scope(scope Scope) Queryable {
+	_q := q
+	_q.scope = scope
+	return _q
+}
+
+// relation returns the name of the relation q reads in its scope.
+func (q Queryable) relation() string {
+	if q.scope != ScopeUser {
+		if def, ok := lookupTarget(q.Target); ok {
+			if relation, ok := def.ScopedRelations[q.scope]; ok {
+				return relation
+			}
+		}
+	}
+	return q.Target.String()
+}
+
+func (q Queryable) validateScope() error {
+	if q.scope == ScopeUser {
+		return nil
+	}
+	def, _ := lookupTarget(q.Target)
+	if _, ok := def.ScopedRelations[q.scope]; !ok {
+		return fmt.Errorf("%s has no %s variant", q.Target, q.scope)
+	}
+	return nil
+}
+
+// realias rewrites the columns of q qualified with its relation name in a user
+// given clause, with the name of the relation in its scope or not, so that they
+// refer to the alias the relation got in the query.
+func (q Queryable) realias(clause, alias string) string {
+	clause = realias(clause, q.Target.String(), alias)
+	if relation := q.relation(); relation != q.Target.String() {
+		clause = realias(clause, relation, alias)
+	}
+	return clause
+}
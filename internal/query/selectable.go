@@ -3,7 +3,19 @@ package query
 // Selectable is the interface describing the relations
 // a select query can be run for.
 type Selectable interface {
-	// Selects returns a list of column names as appeared
-	// in a select query.
+	// Selects returns a list of unqualified column names as appeared
+	// in a select query. They're qualified with the relation alias
+	// when the query is generated.
 	Selects() []string
 }
+
+// ExpressionSelectable is implemented by the Selectables having columns
+// computed by SQL expressions, rather than read from the relation as they are.
+// Expressions maps the names of these columns to their expressions, which
+// refer to the columns of the relation qualified with its name, e.g.
+//  age(pg_stat_activity.backend_xmin)
+// Computed columns can be ordered on, but not referred to in Where clauses.
+type ExpressionSelectable interface {
+	Selectable
+	Expressions() map[string]string
+}
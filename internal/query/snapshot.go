@@ -0,0 +1,43 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ToSnapshotQuery converts the queryables to a single query returning one row,
+// so that they are all read from the same snapshot of the statistics. The row
+// holds the capture timestamp, followed by the rows of each queryable
+// aggregated into a JSON array. Each target can be included once only. The
+// arguments bound to the placeholders of the query are returned along with it.
+func ToSnapshotQuery(queryables []Queryable) (string, []interface{}, error) {
+	var b builder
+	seen := make(map[Target]bool, len(queryables))
+	selects := make([]string, 0, len(queryables)+1)
+	selects = append(selects, "now() AS captured_at")
+
+	for _, q := range queryables {
+		if seen[q.Target] {
+			return "", nil, fmt.Errorf("%s is included more than once in the snapshot", q.Target)
+		}
+		seen[q.Target] = true
+
+		if q.mutualRecursionDetected() {
+			return "", nil, errMutualRecursionDetected
+		}
+
+		b.excludeOwnBackend = q.excludeOwnBackend
+		alias := b.nextAlias(q.relation())
+		sq, err := b.build(q, q.relation(), JoinStrategyDefault, nil, nil, true)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "converting %s for snapshot", q.Target)
+		}
+		selects = append(selects, fmt.Sprintf(`(SELECT COALESCE(json_agg(%[1]s), '[]') FROM (
+				%[2]s
+			) AS %[1]s) AS %[1]s`, alias, sq.sql))
+	}
+
+	return fmt.Sprintf("SELECT\n\t%s", strings.Join(selects, ",\n\t")), b.args, nil
+}
@@ -1,9 +1,8 @@
 package query
 
-import "fmt"
-
 // Target represents a single, non-recursive queryable target.
 // It's usually a relation (tables, views) but can be a function result.
+// Targets other than the built-in ones can be added with RegisterTarget.
 type Target int
 
 // Targets defined
@@ -28,239 +27,324 @@ const (
 	TargetStatArchiver
 	TargetStatBGWriter
 	TargetStatSLRU
+	TargetStatStatements
+	TargetStatProgressVacuum
+	TargetStatProgressAnalyze
+	TargetStatProgressCreateIndex
+	TargetStatProgressCluster
+	TargetStatProgressBasebackup
+	TargetReplicationSlots
+	TargetPreparedXacts
+	TargetStatXactUserTables
+	TargetStatXactUserFunctions
 
 	TargetBlockingPIDs
+	TargetSafeSnapshotBlockingPIDs
+	TargetBackendXIDAge
+	TargetBackendXMinAge
+	TargetTotalRelationSize
+	TargetRelationSize
+	TargetIndexesSize
+	TargetDatabaseSize
+	TargetWALLSNDiff
+	TargetXLogLocationDiff
 
 	numTargets
 )
 
 // String returns the stringified target.
 func (t Target) String() string {
-	return [numTargets]string{
-		"",
-		"pg_locks",
-		"pg_locks",
-		"pg_stat_activity",
-		"pg_stat_replication",
-		"pg_stat_ssl",
-		"pg_stat_gssapi",
-		"pg_stat_wal_receiver",
-		"pg_stat_subscription",
-		"pg_stat_database",
-		"pg_stat_database_conflicts",
-		"pg_stat_user_tables",
-		"pg_stat_user_indexes",
-		"pg_statio_user_indexes",
-		"pg_statio_user_sequences",
-		"pg_statio_user_tables",
-		"pg_stat_user_functions",
-		"pg_stat_archiver",
-		"pg_stat_bgwriter",
-		"pg_stat_slru",
-
-		"",
-	}[t]
+	def, ok := lookupTarget(t)
+	if !ok || def.Function {
+		return ""
+	}
+	return def.Relation
 }
 
-// GetJoinClauses returns the relation alias, column alias, and join condition
-// between the target and the target it's being joined with.
-func (t Target) GetJoinClauses(join Target) (string, string, string, error) {
-	var selectClause, alias, columnAlias, joinCondition string
-	var err error
-
-	switch j := (joiner{from: t, join: join}); j {
-
-	// Joined with StatActivity
-	case joiner{from: TargetStatActivity, join: TargetLocks}:
-		alias = "l"
-		columnAlias = "locks"
-		joinCondition = "l.pid = pg_stat_activity.pid"
-	case joiner{from: TargetStatActivity, join: TargetLocksOnTxID}:
-		alias = "txlock"
-		columnAlias = "tx_locks"
-		joinCondition = "txlock.transactionid = pg_stat_activity.backend_xid"
-	case joiner{from: TargetStatActivity, join: TargetStatSSL}:
-		alias = "ssl"
-		columnAlias = "ssl_usages"
-		joinCondition = "ssl.pid = pg_stat_activity.pid"
-	case joiner{from: TargetStatActivity, join: TargetStatGSSAPI}:
-		alias = "gssapi"
-		columnAlias = "gssapi_usages"
-		joinCondition = "gssapi.pid = pg_stat_activity.pid"
-	case joiner{from: TargetStatActivity, join: TargetStatWALReceiver}:
-		alias = "wal_receiver"
-		columnAlias = "wal_receivers"
-		joinCondition = "wal_receiver.pid = pg_stat_activity.pid"
-	case joiner{from: TargetStatActivity, join: TargetStatSubscription}:
-		alias = "subscription"
-		columnAlias = "subscriptions"
-		joinCondition = "subscription.pid = pg_stat_activity.pid"
-	case joiner{from: TargetStatActivity, join: TargetStatDatabase}:
-		alias = "sa_database"
-		columnAlias = "databases"
-		joinCondition = "sa_database.datid = pg_stat_activity.datid"
-	case joiner{from: TargetStatActivity, join: TargetStatDatabaseConflicts}:
-		alias = "database_conflict"
-		columnAlias = "database_conflicts"
-		joinCondition = "database_conflict.datid = pg_stat_activity.datid"
-	case joiner{from: TargetStatActivity, join: TargetBlockingPIDs}:
-		return "pg_blocking_pids(pg_stat_activity.pid) AS blocked_by", "", "", nil
-
-	// Joined with StatReplication
-	case joiner{from: TargetStatReplication, join: TargetLocks}:
-		alias = "l"
-		columnAlias = "locks"
-		joinCondition = "l.pid = pg_stat_replication.pid"
-	case joiner{from: TargetStatReplication, join: TargetStatSSL}:
-		alias = "ssl"
-		columnAlias = "ssl_usages"
-		joinCondition = "ssl.pid = pg_stat_replication.pid"
-	case joiner{from: TargetStatReplication, join: TargetStatGSSAPI}:
-		alias = "gssapi"
-		columnAlias = "gssapi_usages"
-		joinCondition = "gssapi.pid = pg_stat_replication.pid"
-	case joiner{from: TargetStatReplication, join: TargetStatWALReceiver}:
-		alias = "wal_receiver"
-		columnAlias = "wal_receivers"
-		joinCondition = "wal_receiver.pid = pg_stat_replication.pid"
-
-	// Joined with StatUserTables
-	case joiner{from: TargetStatUserTables, join: TargetLocks}:
-		alias = "l"
-		columnAlias = "locks"
-		joinCondition = "l.relation = pg_stat_user_tables.relid"
-	case joiner{from: TargetStatUserTables, join: TargetStatUserIndexes}:
-		alias = "userindex"
-		columnAlias = "indexes"
-		joinCondition = "userindex.relid = pg_stat_user_tables.relid"
-	case joiner{from: TargetStatUserTables, join: TargetStatSubscription}:
-		alias = "subscr"
-		columnAlias = "subscriptions"
-		joinCondition = "subscr.relid = pg_stat_user_tables.relid"
-	case joiner{from: TargetStatUserTables, join: TargetStatIOUserIndexes}:
-		alias = "userindex_io"
-		columnAlias = "sequence_iostats"
-		joinCondition = "userindex_io.relid = pg_stat_user_tables.relid"
-	case joiner{from: TargetStatUserTables, join: TargetStatIOUserSequences}:
-		alias = "usersequence_io"
-		columnAlias = "sequence_iostats"
-		joinCondition = "usersequence_io.relid = pg_stat_user_tables.relid"
-	case joiner{from: TargetStatUserTables, join: TargetStatIOUserTables}:
-		alias = "usertable_io"
-		columnAlias = "table_iostats"
-		joinCondition = "usertable_io.relid = pg_stat_user_tables.relid"
-
-	// Joined with Locks
-	case joiner{from: TargetLocks, join: TargetStatActivity}:
-		alias = "locks_sa"
-		columnAlias = "activities"
-		joinCondition = "locks_sa.pid = pg_locks.pid"
-	case joiner{from: TargetLocks, join: TargetStatDatabase}:
-		alias = "locks_database"
-		columnAlias = "databases"
-		joinCondition = "locks_database.datid = pg_locks.database"
-	case joiner{from: TargetLocks, join: TargetStatUserTables}:
-		alias = "locks_table"
-		columnAlias = "tables"
-		joinCondition = "locks_table.relid = pg_locks.relation"
-	case joiner{from: TargetLocks, join: TargetStatUserIndexes}:
-		alias = "locks_index"
-		columnAlias = "indexes"
-		joinCondition = "locks_index.relid = pg_locks.relation"
-	case joiner{from: TargetLocks, join: TargetStatIOUserTables}:
-		alias = "locks_table_io"
-		columnAlias = "tables_io"
-		joinCondition = "locks_table_io.relid = pg_locks.relation"
-	case joiner{from: TargetLocks, join: TargetStatIOUserIndexes}:
-		alias = "locks_index_io"
-		columnAlias = "indexes_io"
-		joinCondition = "locks_index_io.relid = pg_locks.relation"
-	case joiner{from: TargetLocks, join: TargetStatIOUserSequences}:
-		alias = "locks_sequence_io"
-		columnAlias = "sequences_io"
-		joinCondition = "locks_sequence_io.relid = pg_locks.relation"
-
-	// Joined with StatSSL
-	case joiner{from: TargetStatSSL, join: TargetLocks}:
-		alias = "ssl_locks"
-		columnAlias = "locks"
-		joinCondition = "ssl_locks.pid = pg_stat_ssl.pid"
-	case joiner{from: TargetStatSSL, join: TargetStatActivity}:
-		alias = "ssl_activities"
-		columnAlias = "activities"
-		joinCondition = "ssl_activities.pid = pg_stat_ssl.pid"
-
-	// Joined with StatGSSAPI
-	case joiner{from: TargetStatGSSAPI, join: TargetLocks}:
-		alias = "gssapi_locks"
-		columnAlias = "locks"
-		joinCondition = "gssapi_locks.pid = pg_stat_gssapi.pid"
-	case joiner{from: TargetStatGSSAPI, join: TargetStatActivity}:
-		alias = "gssapi_activities"
-		columnAlias = "activities"
-		joinCondition = "gssapi_activities.pid = pg_stat_gssapi.pid"
-
-	// Joined with StatWALReceiver
-	case joiner{from: TargetStatWALReceiver, join: TargetLocks}:
-		alias = "walreceiver_locks"
-		columnAlias = "locks"
-		joinCondition = "walreceiver_locks.pid = pg_stat_wal_receiver.pid"
-	case joiner{from: TargetStatWALReceiver, join: TargetStatActivity}:
-		alias = "walreceiver_activities"
-		columnAlias = "activities"
-		joinCondition = "walreceiver_activities.pid = pg_stat_wal_receiver.pid"
-
-	// Joined with StatDatabase
-	case joiner{from: TargetStatDatabase, join: TargetStatDatabaseConflicts}:
-		alias = "statdb_dbconflicts"
-		columnAlias = "conflicts"
-		joinCondition = "statdb_dbconflicts.datid = pg_stat_database.datid"
-	case joiner{from: TargetStatDatabase, join: TargetLocks}:
-		alias = "statdb_locks"
-		columnAlias = "locks"
-		joinCondition = "statdb_locks.database = pg_stat_database.datid"
-	case joiner{from: TargetStatDatabase, join: TargetStatActivity}:
-		alias = "statdb_activities"
-		columnAlias = "activities"
-		joinCondition = "statdb_activities.datid = pg_stat_database.datid"
-
-	// Joined with StatSubscription
-	case joiner{from: TargetStatSubscription, join: TargetLocks}:
-		alias = "subscription_locks"
-		columnAlias = "locks"
-		joinCondition = "subscription_locks.pid = pg_stat_subscription.pid"
-	case joiner{from: TargetStatSubscription, join: TargetStatActivity}:
-		alias = "subscription_activities"
-		columnAlias = "activities"
-		joinCondition = "subscription_activities.pid = pg_stat_subscription.pid"
-
-	// Joined with StatIndex
-	case joiner{from: TargetStatUserIndexes, join: TargetStatUserTables}:
-		alias = "statindex_tables"
-		columnAlias = "tables"
-		joinCondition = "statindex_tables.relid = pg_stat_user_indexes.relid"
-	case joiner{from: TargetStatUserIndexes, join: TargetStatIOUserTables}:
-		alias = "statindex_tablesio"
-		columnAlias = "tablesio"
-		joinCondition = "statindex_tablesio.relid = pg_stat_user_indexes.relid"
-	case joiner{from: TargetStatUserIndexes, join: TargetLocks}:
-		alias = "statindex_locks"
-		columnAlias = "locks"
-		joinCondition = "statindex_locks.relation = pg_stat_user_indexes.indexrelid"
-	case joiner{from: TargetStatUserIndexes, join: TargetStatIOUserIndexes}:
-		alias = "statindex_indexesio"
-		columnAlias = "indexeso"
-		joinCondition = "statindex_indexesio.indexrelid = pg_stat_user_indexes.indexrelid"
-
-	default:
-		err = j.GetUnsupportedJoinError()
+func init() {
+	builtins := map[Target]TargetDefinition{
+		TargetLocks: {
+			Relation:         "pg_locks",
+			BackendPIDColumn: "pid",
+			Joins: []Join{
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatDatabase, Column: "databases", On: []JoinOn{{Parent: "database", Child: "datid"}}},
+				{Target: TargetStatUserTables, Column: "tables", On: []JoinOn{{Parent: "relation", Child: "relid"}}},
+				{Target: TargetStatUserIndexes, Column: "indexes", On: []JoinOn{{Parent: "relation", Child: "relid"}}},
+				{Target: TargetStatIOUserTables, Column: "tables_io", On: []JoinOn{{Parent: "relation", Child: "relid"}}},
+				{Target: TargetStatIOUserIndexes, Column: "indexes_io", On: []JoinOn{{Parent: "relation", Child: "relid"}}},
+				{Target: TargetStatIOUserSequences, Column: "sequences_io", On: []JoinOn{{Parent: "relation", Child: "relid"}}},
+			},
+		},
+		TargetLocksOnTxID: {
+			Relation:         "pg_locks",
+			BackendPIDColumn: "pid",
+		},
+		TargetStatActivity: {
+			Relation:         "pg_stat_activity",
+			KeyColumns:       []string{"pid"},
+			BackendPIDColumn: "pid",
+			Joins: []Join{
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetLocksOnTxID, Column: "tx_locks", On: []JoinOn{{Parent: "backend_xid", Child: "transactionid"}}},
+				{Target: TargetStatSSL, Column: "ssl_usages", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatGSSAPI, Column: "gssapi_usages", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatWALReceiver, Column: "wal_receivers", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatSubscription, Column: "subscriptions", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatDatabase, Column: "databases", On: []JoinOn{{Parent: "datid", Child: "datid"}}},
+				{Target: TargetStatDatabaseConflicts, Column: "database_conflicts", On: []JoinOn{{Parent: "datid", Child: "datid"}}},
+				{Target: TargetBlockingPIDs, Column: "blocked_by", Args: []string{"pid"}},
+				{Target: TargetSafeSnapshotBlockingPIDs, Column: "safe_snapshot_blocked_by", Args: []string{"pid"}},
+				{Target: TargetBackendXIDAge, Column: "backend_xid_age", Args: []string{"backend_xid"}},
+				{Target: TargetBackendXMinAge, Column: "backend_xmin_age", Args: []string{"backend_xmin"}},
+			},
+		},
+		TargetStatReplication: {
+			Relation:   "pg_stat_replication",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatSSL, Column: "ssl_usages", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatGSSAPI, Column: "gssapi_usages", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatWALReceiver, Column: "wal_receivers", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				// The WAL location to compare with is the last one replayed on a cascading standby.
+				{Target: TargetWALLSNDiff, Column: "replay_lag_bytes", Args: []string{
+					"CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END",
+					"replay_lsn",
+				}},
+				{Target: TargetXLogLocationDiff, Column: "replay_lag_bytes", Args: []string{
+					"CASE WHEN pg_is_in_recovery() THEN pg_last_xlog_replay_location() ELSE pg_current_xlog_location() END",
+					"replay_location",
+				}},
+			},
+		},
+		TargetStatSSL: {
+			Relation:   "pg_stat_ssl",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+			},
+		},
+		TargetStatGSSAPI: {
+			Relation:   "pg_stat_gssapi",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+			},
+		},
+		TargetStatWALReceiver: {
+			Relation:   "pg_stat_wal_receiver",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+			},
+		},
+		TargetStatSubscription: {
+			Relation:   "pg_stat_subscription",
+			KeyColumns: []string{"subid", "relid"},
+			Joins: []Join{
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+			},
+		},
+		TargetStatDatabase: {
+			Relation:   "pg_stat_database",
+			KeyColumns: []string{"datid"},
+			Joins: []Join{
+				{Target: TargetStatDatabaseConflicts, Column: "conflicts", On: []JoinOn{{Parent: "datid", Child: "datid"}}},
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "datid", Child: "database"}}},
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "datid", Child: "datid"}}},
+				{Target: TargetStatStatements, Column: "statements", On: []JoinOn{{Parent: "datid", Child: "dbid"}}},
+				// The row of the shared objects has datid 0, whose size only
+				// superusers may ask for. The strict function gives null instead.
+				{Target: TargetDatabaseSize, Column: "database_size", Args: []string{"NULLIF({parent}.datid, 0::oid)"}},
+			},
+		},
+		TargetStatDatabaseConflicts: {
+			Relation:   "pg_stat_database_conflicts",
+			KeyColumns: []string{"datid"},
+		},
+		TargetStatUserTables: {
+			Relation:        "pg_stat_user_tables",
+			ScopedRelations: map[Scope]string{ScopeSys: "pg_stat_sys_tables", ScopeAll: "pg_stat_all_tables"},
+			KeyColumns:      []string{"relid"},
+			Joins: []Join{
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "relid", Child: "relation"}}},
+				{Target: TargetStatUserIndexes, Column: "indexes", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+				{Target: TargetStatSubscription, Column: "subscriptions", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+				{Target: TargetStatIOUserIndexes, Column: "index_iostats", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+				{Target: TargetStatIOUserSequences, Column: "sequence_iostats", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+				{Target: TargetStatIOUserTables, Column: "table_iostats", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+				{Target: TargetTotalRelationSize, Column: "total_relation_size", Args: []string{"relid"}},
+				{Target: TargetRelationSize, Column: "relation_size", Args: []string{"relid"}},
+				{Target: TargetIndexesSize, Column: "indexes_size", Args: []string{"relid"}},
+			},
+		},
+		TargetStatUserIndexes: {
+			Relation:        "pg_stat_user_indexes",
+			ScopedRelations: map[Scope]string{ScopeSys: "pg_stat_sys_indexes", ScopeAll: "pg_stat_all_indexes"},
+			KeyColumns:      []string{"indexrelid"},
+			Joins: []Join{
+				{Target: TargetStatUserTables, Column: "tables", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+				{Target: TargetStatIOUserTables, Column: "tables_io", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "indexrelid", Child: "relation"}}},
+				{Target: TargetStatIOUserIndexes, Column: "indexes_io", On: []JoinOn{{Parent: "indexrelid", Child: "indexrelid"}}},
+				{Target: TargetTotalRelationSize, Column: "total_relation_size", Args: []string{"indexrelid"}},
+				{Target: TargetRelationSize, Column: "relation_size", Args: []string{"indexrelid"}},
+			},
+		},
+		TargetStatIOUserIndexes: {
+			Relation:        "pg_statio_user_indexes",
+			ScopedRelations: map[Scope]string{ScopeSys: "pg_statio_sys_indexes", ScopeAll: "pg_statio_all_indexes"},
+			KeyColumns:      []string{"indexrelid"},
+		},
+		TargetStatIOUserSequences: {
+			Relation:        "pg_statio_user_sequences",
+			ScopedRelations: map[Scope]string{ScopeSys: "pg_statio_sys_sequences", ScopeAll: "pg_statio_all_sequences"},
+			KeyColumns:      []string{"relid"},
+		},
+		TargetStatIOUserTables: {
+			Relation:        "pg_statio_user_tables",
+			ScopedRelations: map[Scope]string{ScopeSys: "pg_statio_sys_tables", ScopeAll: "pg_statio_all_tables"},
+			KeyColumns:      []string{"relid"},
+		},
+		TargetStatUserFunctions: {
+			Relation:   "pg_stat_user_functions",
+			KeyColumns: []string{"funcid"},
+		},
+		TargetStatArchiver: {
+			Relation: "pg_stat_archiver",
+		},
+		TargetStatBGWriter: {
+			Relation: "pg_stat_bgwriter",
+		},
+		TargetStatSLRU: {
+			Relation:   "pg_stat_slru",
+			KeyColumns: []string{"name"},
+		},
+		// pg_stat_activity only has query_id from Postgres 14 on, so the
+		// statements can't be joined with the backends running them yet.
+		TargetStatStatements: {
+			Relation:   "pg_stat_statements",
+			KeyColumns: []string{"userid", "dbid", "queryid"},
+			Joins: []Join{
+				{Target: TargetStatDatabase, Column: "databases", On: []JoinOn{{Parent: "dbid", Child: "datid"}}},
+			},
+		},
+		TargetStatProgressVacuum: {
+			Relation:   "pg_stat_progress_vacuum",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatUserTables, Column: "tables", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+			},
+		},
+		TargetStatProgressAnalyze: {
+			Relation:   "pg_stat_progress_analyze",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatUserTables, Column: "tables", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+			},
+		},
+		TargetStatProgressCreateIndex: {
+			Relation:   "pg_stat_progress_create_index",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatUserTables, Column: "tables", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+			},
+		},
+		TargetStatProgressCluster: {
+			Relation:   "pg_stat_progress_cluster",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+				{Target: TargetStatUserTables, Column: "tables", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+			},
+		},
+		TargetStatProgressBasebackup: {
+			Relation:   "pg_stat_progress_basebackup",
+			KeyColumns: []string{"pid"},
+			Joins: []Join{
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "pid", Child: "pid"}}},
+			},
+		},
+		TargetReplicationSlots: {
+			Relation:   "pg_replication_slots",
+			KeyColumns: []string{"slot_name"},
+			Joins: []Join{
+				{Target: TargetStatActivity, Column: "activities", On: []JoinOn{{Parent: "active_pid", Child: "pid"}}},
+				{Target: TargetStatReplication, Column: "replications", On: []JoinOn{{Parent: "active_pid", Child: "pid"}}},
+			},
+		},
+		TargetPreparedXacts: {
+			Relation:   "pg_prepared_xacts",
+			KeyColumns: []string{"transaction"},
+			Joins: []Join{
+				{Target: TargetLocks, Column: "locks", On: []JoinOn{{Parent: "transaction", Child: "virtualtransaction", Condition: "{child} = '-1/' || {parent}"}}},
+				{Target: TargetLocksOnTxID, Column: "tx_locks", On: []JoinOn{{Parent: "transaction", Child: "transactionid"}}},
+			},
+		},
+		TargetStatXactUserTables: {
+			Relation:        "pg_stat_xact_user_tables",
+			ScopedRelations: map[Scope]string{ScopeSys: "pg_stat_xact_sys_tables", ScopeAll: "pg_stat_xact_all_tables"},
+			KeyColumns:      []string{"relid"},
+			Joins: []Join{
+				{Target: TargetStatUserTables, Column: "tables", On: []JoinOn{{Parent: "relid", Child: "relid"}}},
+			},
+		},
+		TargetStatXactUserFunctions: {
+			Relation:   "pg_stat_xact_user_functions",
+			KeyColumns: []string{"funcid"},
+		},
+		TargetBlockingPIDs: {
+			Relation: "pg_blocking_pids",
+			Function: true,
+		},
+		TargetSafeSnapshotBlockingPIDs: {
+			Relation: "pg_safe_snapshot_blocking_pids",
+			Function: true,
+		},
+		TargetBackendXIDAge: {
+			Relation: "age",
+			Function: true,
+		},
+		TargetBackendXMinAge: {
+			Relation: "age",
+			Function: true,
+		},
+		TargetTotalRelationSize: {
+			Relation: "pg_total_relation_size",
+			Function: true,
+		},
+		TargetRelationSize: {
+			Relation: "pg_relation_size",
+			Function: true,
+		},
+		TargetIndexesSize: {
+			Relation: "pg_indexes_size",
+			Function: true,
+		},
+		TargetDatabaseSize: {
+			Relation: "pg_database_size",
+			Function: true,
+		},
+		TargetWALLSNDiff: {
+			Relation: "pg_wal_lsn_diff",
+			Function: true,
+		},
+		TargetXLogLocationDiff: {
+			Relation: "pg_xlog_location_diff",
+			Function: true,
+		},
 	}
 
-	selectClause = fmt.Sprintf(
-		"(CASE WHEN count(%[1]s) = 0 THEN '[]' ELSE json_agg(%[1]s) END) AS %s",
-		alias,
-		columnAlias,
-	)
-
-	return selectClause, alias, joinCondition, err
+	for t := TargetUnspecified + 1; t < numTargets; t++ {
+		def := builtins[t]
+		registry.targets = append(registry.targets, &def)
+	}
 }
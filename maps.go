@@ -0,0 +1,82 @@
+package pogo
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo/internal/query"
+)
+
+// ForMaps is like For, except it reads the rows into maps keyed by column name,
+// for tools displaying any queryable without knowing the struct to scan into.
+// The joined rows are decoded into slices of maps, at any depth, and the JSON
+// numbers in them into json.Number. The other columns hold the values given by
+// the driver, with the bytes converted to strings.
+func (qr QueryRunner) ForMaps(queryable query.Queryable) ([]map[string]interface{}, error) {
+	layout, err := queryable.Layout()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting layout of queryable")
+	}
+
+	rows, err := qr.Rows(queryable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ms := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(layout.Columns))
+		dests := make([]interface{}, len(values))
+		for i := range values {
+			dests[i] = &values[i]
+		}
+		if err := rows.Scan(dests...); err != nil {
+			return nil, errors.Wrap(err, "scanning row")
+		}
+
+		m := make(map[string]interface{}, len(layout.Columns))
+		for i, column := range layout.Columns {
+			if _, ok := layout.Joins[column]; ok {
+				if m[column], err = decodeJoinedRows(values[i]); err != nil {
+					return nil, errors.Wrapf(err, "decoding rows of %s", column)
+				}
+				continue
+			}
+			if b, ok := values[i].([]byte); ok {
+				m[column] = string(b)
+				continue
+			}
+			m[column] = values[i]
+		}
+		ms = append(ms, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading rows")
+	}
+
+	return ms, nil
+}
+
+// decodeJoinedRows decodes the JSON array of joined rows, unless the driver
+// already did.
+func decodeJoinedRows(value interface{}) (interface{}, error) {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return value, nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	var rows []interface{}
+	if err := d.Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,49 @@
+package pogo
+
+import "github.com/sanggonlee/pogo/internal/query"
+
+// Option modifies the top-level queryable of the convenience methods,
+// such as StatActivity13. See QueryRunner.Options.
+type Option func(Queryable) Queryable
+
+// Order is an ordering on a column of a queryable.
+type Order = query.Order
+
+// Asc orders by the column in ascending order.
+func Asc(column string) Order {
+	return query.Asc(column)
+}
+
+// Desc orders by the column in descending order.
+func Desc(column string) Order {
+	return query.Desc(column)
+}
+
+// OrderBy orders the resulting rows by the given columns.
+func OrderBy(orders ...Order) Option {
+	return func(q Queryable) Queryable {
+		return q.OrderBy(orders...)
+	}
+}
+
+// Limit limits the number of resulting rows to n.
+func Limit(n int) Option {
+	return func(q Queryable) Queryable {
+		return q.Limit(n)
+	}
+}
+
+// After restricts the resulting rows to the ones coming after the cursor,
+// in the order given by OrderBy.
+func After(cursor ...interface{}) Option {
+	return func(q Queryable) Queryable {
+		return q.After(cursor...)
+	}
+}
+
+// Columns restricts the selected columns to the given ones.
+func Columns(columns ...string) Option {
+	return func(q Queryable) Queryable {
+		return q.Columns(columns...)
+	}
+}
@@ -0,0 +1,42 @@
+// Package pogootel instruments pogo with OpenTelemetry: TracingHooks trace the
+// queries pogo runs, and RegisterMetrics reports the statistics pogo reads as
+// asynchronous metrics.
+//
+// The metrics follow the semantic convention below. Names are prefixed with
+// postgresql, units follow UCUM, and the counters are cumulative since the
+// last reset of the statistics in Postgres.
+//
+// Counters, from pg_stat_database, with the postgresql.database.name attribute
+// (absent for the row of the shared objects):
+//
+//	postgresql.database.transactions  {transaction}  postgresql.transaction.outcome: commit, rollback
+//	postgresql.database.blocks        {block}        postgresql.block.source: read, hit
+//	postgresql.database.tuples        {tuple}        postgresql.tuple.operation: returned, fetched, inserted, updated, deleted
+//	postgresql.database.conflicts     {conflict}
+//	postgresql.database.deadlocks     {deadlock}
+//	postgresql.database.temp.files    {file}
+//	postgresql.database.temp.size     By
+//
+// Counters, from pg_stat_bgwriter:
+//
+//	postgresql.bgwriter.checkpoints        {checkpoint}  postgresql.checkpoint.trigger: timed, requested
+//	postgresql.bgwriter.checkpoint.time    s             postgresql.checkpoint.phase: write, sync
+//	postgresql.bgwriter.buffers.written    {buffer}      postgresql.buffer.writer: checkpoint, bgwriter, backend
+//	postgresql.bgwriter.buffers.allocated  {buffer}
+//	postgresql.bgwriter.backend.fsyncs     {fsync}
+//	postgresql.bgwriter.maxwritten         {stop}
+//
+// Gauge, from pg_stat_activity, with the postgresql.database.name and
+// postgresql.connection.state attributes (e.g. active, idle, idle in transaction):
+//
+//	postgresql.connections  {connection}
+//
+// Histogram, from pg_stat_activity, with the same attributes, of the ages of
+// the open transactions. There are no asynchronous histograms, so it's
+// recorded at each collection, with the age of every transaction open then: a
+// transaction open across collections is recorded at each of them.
+//
+//	postgresql.transaction.age  s
+//
+// pogo's own backend is left out of pg_stat_activity.
+package pogootel
@@ -0,0 +1,26 @@
+module github.com/sanggonlee/pogo/otel
+
+go 1.21
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/sanggonlee/pogo v0.2.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/metric v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/sdk/metric v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+	gopkg.in/guregu/null.v3 v3.5.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/lib/pq v1.10.2 // indirect
+	github.com/teepark/pqinterval v1.0.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
+
+// Build against the pogo in this repository until v0.2.0 is tagged.
+replace github.com/sanggonlee/pogo => ../
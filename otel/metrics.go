@@ -0,0 +1,376 @@
+package pogootel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo"
+	"github.com/sanggonlee/pogo/postgres13"
+	"github.com/sanggonlee/pogo/postgres9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"gopkg.in/guregu/null.v3"
+)
+
+// Attribute keys set on the metrics.
+const (
+	AttributeDatabaseName       = attribute.Key("postgresql.database.name")
+	AttributeTransactionOutcome = attribute.Key("postgresql.transaction.outcome")
+	AttributeBlockSource        = attribute.Key("postgresql.block.source")
+	AttributeTupleOperation     = attribute.Key("postgresql.tuple.operation")
+	AttributeCheckpointTrigger  = attribute.Key("postgresql.checkpoint.trigger")
+	AttributeCheckpointPhase    = attribute.Key("postgresql.checkpoint.phase")
+	AttributeBufferWriter       = attribute.Key("postgresql.buffer.writer")
+	AttributeConnectionState    = attribute.Key("postgresql.connection.state")
+)
+
+// RunnerFunc returns the runner to read the statistics with, for the context
+// of a collection. For example:
+//
+//	func(ctx context.Context) pogo.QueryRunner {
+//		return pogo.QueryContext(ctx, db)
+//	}
+type RunnerFunc func(ctx context.Context) pogo.QueryRunner
+
+// RegisterMetrics registers the instruments of the metrics documented in the
+// package on a meter of the provider, or of the global one if nil. At each
+// collection, the statistics are read in a single snapshot query with the
+// runner returned by runner. Unregister the returned registration to stop.
+func RegisterMetrics(mp metric.MeterProvider, runner RunnerFunc) (metric.Registration, error) {
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	m := &metrics{runner: runner}
+	if err := m.create(mp.Meter(instrumentationName)); err != nil {
+		return nil, err
+	}
+
+	return mp.Meter(instrumentationName).RegisterCallback(
+		m.observe,
+		m.transactions,
+		m.blocks,
+		m.tuples,
+		m.conflicts,
+		m.deadlocks,
+		m.tempFiles,
+		m.tempSize,
+		m.checkpoints,
+		m.checkpointTime,
+		m.buffersWritten,
+		m.buffersAllocated,
+		m.backendFsyncs,
+		m.maxWritten,
+		m.connections,
+	)
+}
+
+// metrics holds the instruments, and reads the statistics for them.
+type metrics struct {
+	runner RunnerFunc
+
+	transactions     metric.Int64ObservableCounter
+	blocks           metric.Int64ObservableCounter
+	tuples           metric.Int64ObservableCounter
+	conflicts        metric.Int64ObservableCounter
+	deadlocks        metric.Int64ObservableCounter
+	tempFiles        metric.Int64ObservableCounter
+	tempSize         metric.Int64ObservableCounter
+	checkpoints      metric.Int64ObservableCounter
+	checkpointTime   metric.Float64ObservableCounter
+	buffersWritten   metric.Int64ObservableCounter
+	buffersAllocated metric.Int64ObservableCounter
+	backendFsyncs    metric.Int64ObservableCounter
+	maxWritten       metric.Int64ObservableCounter
+	connections      metric.Int64ObservableGauge
+
+	// transactionAge is synchronous, since there are no observable
+	// histograms. It's recorded by the callback of the other instruments.
+	transactionAge metric.Float64Histogram
+}
+
+// transactionAgeBuckets are the bucket boundaries of the transaction ages in
+// seconds, from short transactions to the ones holding back vacuum for hours.
+var transactionAgeBuckets = []float64{0.1, 1, 5, 10, 30, 60, 300, 600, 1800, 3600, 21600, 86400}
+
+func (m *metrics) create(meter metric.Meter) error {
+	counters := []struct {
+		dest        *metric.Int64ObservableCounter
+		name, unit  string
+		description string
+	}{
+		{&m.transactions, "postgresql.database.transactions", "{transaction}", "Transactions committed or rolled back in the database."},
+		{&m.blocks, "postgresql.database.blocks", "{block}", "Disk blocks read or found in the buffer cache in the database."},
+		{&m.tuples, "postgresql.database.tuples", "{tuple}", "Rows returned, fetched, inserted, updated or deleted by queries in the database."},
+		{&m.conflicts, "postgresql.database.conflicts", "{conflict}", "Queries canceled due to conflicts with recovery in the database."},
+		{&m.deadlocks, "postgresql.database.deadlocks", "{deadlock}", "Deadlocks detected in the database."},
+		{&m.tempFiles, "postgresql.database.temp.files", "{file}", "Temporary files created by queries in the database."},
+		{&m.tempSize, "postgresql.database.temp.size", "By", "Data written to temporary files by queries in the database."},
+		{&m.checkpoints, "postgresql.bgwriter.checkpoints", "{checkpoint}", "Checkpoints performed."},
+		{&m.buffersWritten, "postgresql.bgwriter.buffers.written", "{buffer}", "Buffers written by checkpoints, the background writer or backends."},
+		{&m.buffersAllocated, "postgresql.bgwriter.buffers.allocated", "{buffer}", "Buffers allocated."},
+		{&m.backendFsyncs, "postgresql.bgwriter.backend.fsyncs", "{fsync}", "Fsync calls executed by backends themselves."},
+		{&m.maxWritten, "postgresql.bgwriter.maxwritten", "{stop}", "Times the background writer stopped a cleaning scan for writing too many buffers."},
+	}
+	var err error
+	for _, c := range counters {
+		*c.dest, err = meter.Int64ObservableCounter(c.name, metric.WithUnit(c.unit), metric.WithDescription(c.description))
+		if err != nil {
+			return errors.Wrapf(err, "creating %s", c.name)
+		}
+	}
+
+	m.checkpointTime, err = meter.Float64ObservableCounter(
+		"postgresql.bgwriter.checkpoint.time",
+		metric.WithUnit("s"),
+		metric.WithDescription("Time spent writing and syncing the files of checkpoints."),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating postgresql.bgwriter.checkpoint.time")
+	}
+	m.connections, err = meter.Int64ObservableGauge(
+		"postgresql.connections",
+		metric.WithUnit("{connection}"),
+		metric.WithDescription("Client connections by database and state."),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating postgresql.connections")
+	}
+	m.transactionAge, err = meter.Float64Histogram(
+		"postgresql.transaction.age",
+		metric.WithUnit("s"),
+		metric.WithDescription("Ages of the open transactions by database and state, at each collection."),
+		metric.WithExplicitBucketBoundaries(transactionAgeBuckets...),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating postgresql.transaction.age")
+	}
+	return nil
+}
+
+// sample is the version independent part of a snapshot the metrics are read from.
+type sample struct {
+	capturedAt time.Time
+	databases  []databaseSample
+	bgwriters  []bgwriterSample
+	activities []activitySample
+}
+
+type databaseSample struct {
+	name                                          null.String
+	commits, rollbacks, blocksRead, blocksHit     pogo.BigInt
+	returned, fetched, inserted, updated, deleted pogo.BigInt
+	conflicts, deadlocks, tempFiles, tempBytes    pogo.BigInt
+}
+
+type bgwriterSample struct {
+	checkpointsTimed, checkpointsReq                   pogo.BigInt
+	checkpointWriteTime, checkpointSyncTime            null.Float
+	buffersCheckpoint, buffersClean, buffersBackend    pogo.BigInt
+	buffersBackendFsync, buffersAlloc, maxWrittenClean pogo.BigInt
+}
+
+type activitySample struct {
+	database, state null.String
+	xactStart       null.Time
+}
+
+// read reads a sample of the statistics in a snapshot.
+func (m *metrics) read(ctx context.Context) (sample, error) {
+	// The views are only set up for the version once the runner is created.
+	runner := m.runner(ctx)
+	databaseView := pogo.StatDatabaseView
+	bgwriterView := pogo.StatBGWriterView
+	activityView := pogo.StatActivityView.
+		Columns("datname", "state", "xact_start").
+		Where("pg_stat_activity.state IS NOT NULL").
+		ExcludeOwnBackend()
+
+	switch v := pogo.GetPostgresVersion(); v {
+	case pogo.Postgres13:
+		s, err := runner.Snapshot13(databaseView, bgwriterView, activityView)
+		if err != nil {
+			return sample{}, err
+		}
+		return sample13(s), nil
+	case pogo.Postgres9:
+		s, err := runner.Snapshot9(databaseView, bgwriterView, activityView)
+		if err != nil {
+			return sample{}, err
+		}
+		return sample9(s), nil
+	default:
+		return sample{}, fmt.Errorf("postgres version %d is not supported", v)
+	}
+}
+
+func (m *metrics) observe(ctx context.Context, o metric.Observer) error {
+	s, err := m.read(ctx)
+	if err != nil {
+		return errors.Wrap(err, "reading pogo snapshot for metrics")
+	}
+
+	for _, d := range s.databases {
+		var attrs []attribute.KeyValue
+		if d.name.Valid {
+			attrs = append(attrs, AttributeDatabaseName.String(d.name.String))
+		}
+		observeInt(o, m.transactions, d.commits, attrs, AttributeTransactionOutcome.String("commit"))
+		observeInt(o, m.transactions, d.rollbacks, attrs, AttributeTransactionOutcome.String("rollback"))
+		observeInt(o, m.blocks, d.blocksRead, attrs, AttributeBlockSource.String("read"))
+		observeInt(o, m.blocks, d.blocksHit, attrs, AttributeBlockSource.String("hit"))
+		observeInt(o, m.tuples, d.returned, attrs, AttributeTupleOperation.String("returned"))
+		observeInt(o, m.tuples, d.fetched, attrs, AttributeTupleOperation.String("fetched"))
+		observeInt(o, m.tuples, d.inserted, attrs, AttributeTupleOperation.String("inserted"))
+		observeInt(o, m.tuples, d.updated, attrs, AttributeTupleOperation.String("updated"))
+		observeInt(o, m.tuples, d.deleted, attrs, AttributeTupleOperation.String("deleted"))
+		observeInt(o, m.conflicts, d.conflicts, attrs)
+		observeInt(o, m.deadlocks, d.deadlocks, attrs)
+		observeInt(o, m.tempFiles, d.tempFiles, attrs)
+		observeInt(o, m.tempSize, d.tempBytes, attrs)
+	}
+
+	for _, b := range s.bgwriters {
+		observeInt(o, m.checkpoints, b.checkpointsTimed, nil, AttributeCheckpointTrigger.String("timed"))
+		observeInt(o, m.checkpoints, b.checkpointsReq, nil, AttributeCheckpointTrigger.String("requested"))
+		observeMilliseconds(o, m.checkpointTime, b.checkpointWriteTime, AttributeCheckpointPhase.String("write"))
+		observeMilliseconds(o, m.checkpointTime, b.checkpointSyncTime, AttributeCheckpointPhase.String("sync"))
+		observeInt(o, m.buffersWritten, b.buffersCheckpoint, nil, AttributeBufferWriter.String("checkpoint"))
+		observeInt(o, m.buffersWritten, b.buffersClean, nil, AttributeBufferWriter.String("bgwriter"))
+		observeInt(o, m.buffersWritten, b.buffersBackend, nil, AttributeBufferWriter.String("backend"))
+		observeInt(o, m.buffersAllocated, b.buffersAlloc, nil)
+		observeInt(o, m.backendFsyncs, b.buffersBackendFsync, nil)
+		observeInt(o, m.maxWritten, b.maxWrittenClean, nil)
+	}
+
+	type connectionKey struct {
+		database, state string
+	}
+	connections := make(map[connectionKey]int64)
+	for _, a := range s.activities {
+		connections[connectionKey{database: a.database.String, state: a.state.String}]++
+
+		if a.xactStart.Valid {
+			m.transactionAge.Record(ctx, s.capturedAt.Sub(a.xactStart.Time).Seconds(), metric.WithAttributes(
+				AttributeDatabaseName.String(a.database.String),
+				AttributeConnectionState.String(a.state.String),
+			))
+		}
+	}
+	for key, n := range connections {
+		o.ObserveInt64(m.connections, n, metric.WithAttributes(
+			AttributeDatabaseName.String(key.database),
+			AttributeConnectionState.String(key.state),
+		))
+	}
+
+	return nil
+}
+
+// observeInt observes the value of a counter, unless it's null.
+func observeInt(o metric.Observer, c metric.Int64Observable, v pogo.BigInt, attrs []attribute.KeyValue, extra ...attribute.KeyValue) {
+	if !v.Valid {
+		return
+	}
+	all := make([]attribute.KeyValue, 0, len(attrs)+len(extra))
+	all = append(append(all, attrs...), extra...)
+	o.ObserveInt64(c, v.Int64, metric.WithAttributes(all...))
+}
+
+// observeMilliseconds observes a time in milliseconds as seconds, unless it's null.
+func observeMilliseconds(o metric.Observer, c metric.Float64Observable, v null.Float, attrs ...attribute.KeyValue) {
+	if !v.Valid {
+		return
+	}
+	o.ObserveFloat64(c, v.Float64/1000, metric.WithAttributes(attrs...))
+}
+
+func sample13(s postgres13.Snapshot) sample {
+	smp := sample{capturedAt: s.CapturedAt}
+	for _, d := range s.StatDatabases {
+		smp.databases = append(smp.databases, databaseSample{
+			name:       d.DatName,
+			commits:    d.XactCommit,
+			rollbacks:  d.XactRollback,
+			blocksRead: d.BlocksRead,
+			blocksHit:  d.BlocksHit,
+			returned:   d.TuplesReturned,
+			fetched:    d.TuplesFetched,
+			inserted:   d.TuplesInserted,
+			updated:    d.TuplesUpdated,
+			deleted:    d.TuplesDeleted,
+			conflicts:  d.StatDatabase.Conflicts,
+			deadlocks:  d.Deadlocks,
+			tempFiles:  d.TempFiles,
+			tempBytes:  d.TempBytes,
+		})
+	}
+	for _, b := range s.StatBGWriters {
+		smp.bgwriters = append(smp.bgwriters, bgwriterSample{
+			checkpointsTimed:    b.CheckpointsTimed,
+			checkpointsReq:      b.CheckpointsReq,
+			checkpointWriteTime: b.CheckpointWriteTime,
+			checkpointSyncTime:  b.CheckpointSyncTime,
+			buffersCheckpoint:   b.BuffersCheckpoint,
+			buffersClean:        b.BuffersClean,
+			buffersBackend:      b.BuffersBackend,
+			buffersBackendFsync: b.BuffersBackendFsync,
+			buffersAlloc:        b.BuffersAlloc,
+			maxWrittenClean:     b.MaxWrittenClean,
+		})
+	}
+	for _, a := range s.StatActivities {
+		smp.activities = append(smp.activities, activitySample{
+			database:  a.DatName,
+			state:     a.State,
+			xactStart: a.XactStart,
+		})
+	}
+	return smp
+}
+
+func sample9(s postgres9.Snapshot) sample {
+	smp := sample{capturedAt: s.CapturedAt}
+	for _, d := range s.StatDatabases {
+		smp.databases = append(smp.databases, databaseSample{
+			name:       d.DatName,
+			commits:    d.XactCommit,
+			rollbacks:  d.XactRollback,
+			blocksRead: d.BlocksRead,
+			blocksHit:  d.BlocksHit,
+			returned:   d.TuplesReturned,
+			fetched:    d.TuplesFetched,
+			inserted:   d.TuplesInserted,
+			updated:    d.TuplesUpdated,
+			deleted:    d.TuplesDeleted,
+			conflicts:  d.StatDatabase.Conflicts,
+			deadlocks:  d.Deadlocks,
+			tempFiles:  d.TempFiles,
+			tempBytes:  d.TempBytes,
+		})
+	}
+	for _, b := range s.StatBGWriters {
+		smp.bgwriters = append(smp.bgwriters, bgwriterSample{
+			checkpointsTimed:    b.CheckpointsTimed,
+			checkpointsReq:      b.CheckpointsReq,
+			checkpointWriteTime: b.CheckpointWriteTime,
+			checkpointSyncTime:  b.CheckpointSyncTime,
+			buffersCheckpoint:   b.BuffersCheckpoint,
+			buffersClean:        b.BuffersClean,
+			buffersBackend:      b.BuffersBackend,
+			buffersBackendFsync: b.BuffersBackendFsync,
+			buffersAlloc:        b.BuffersAlloc,
+			maxWrittenClean:     b.MaxWrittenClean,
+		})
+	}
+	for _, a := range s.StatActivities {
+		smp.activities = append(smp.activities, activitySample{
+			database:  a.DatName,
+			state:     a.State,
+			xactStart: a.XactStart,
+		})
+	}
+	return smp
+}
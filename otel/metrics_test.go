@@ -0,0 +1,115 @@
+package pogootel_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sanggonlee/pogo"
+	pogootel "github.com/sanggonlee/pogo/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	capturedAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	queryor := &snapshotQueryor{
+		capturedAt: capturedAt,
+		views: []string{
+			`[{"datname": "app", "xact_commit": 10, "xact_rollback": 2, "deadlocks": 1}]`,
+			`[{"checkpoints_timed": 3, "checkpoint_write_time": 1500}]`,
+			`[{"datname": "app", "state": "active", "xact_start": "2021-03-01T11:59:30Z"},
+			  {"datname": "app", "state": "active", "xact_start": "2021-03-01T11:58:00Z"},
+			  {"datname": "app", "state": "active", "xact_start": null},
+			  {"datname": "app", "state": "idle"}]`,
+		},
+	}
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	registration, err := pogootel.RegisterMetrics(provider, func(ctx context.Context) pogo.QueryRunner {
+		return pogo.QueryWithContext(ctx, queryor)
+	})
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	defer registration.Unregister()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	got := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = m.Data
+		}
+	}
+
+	transactions, ok := got["postgresql.database.transactions"].(metricdata.Sum[int64])
+	if !ok || len(transactions.DataPoints) != 2 || !transactions.IsMonotonic {
+		t.Errorf("Expected monotonic transactions by outcome but got %+v", got["postgresql.database.transactions"])
+	}
+	checkpointTime, ok := got["postgresql.bgwriter.checkpoint.time"].(metricdata.Sum[float64])
+	if !ok || len(checkpointTime.DataPoints) != 1 || checkpointTime.DataPoints[0].Value != 1.5 {
+		t.Errorf("Expected checkpoint write time of 1.5s but got %+v", got["postgresql.bgwriter.checkpoint.time"])
+	}
+	connections, ok := got["postgresql.connections"].(metricdata.Gauge[int64])
+	if !ok || len(connections.DataPoints) != 2 {
+		t.Errorf("Expected connections in 2 states but got %+v", got["postgresql.connections"])
+	}
+	ages, ok := got["postgresql.transaction.age"].(metricdata.Histogram[float64])
+	if !ok || len(ages.DataPoints) != 1 {
+		t.Fatalf("Expected transaction ages in 1 state but got %+v", got["postgresql.transaction.age"])
+	}
+	if p := ages.DataPoints[0]; p.Count != 2 || p.Sum != 150 {
+		t.Errorf("Expected transaction ages of 30s and 120s but got %d summing to %v", p.Count, p.Sum)
+	}
+
+	if !strings.Contains(queryor.query, "pg_stat_activity") || !strings.Contains(queryor.query, "pg_backend_pid()") {
+		t.Errorf("Expected pogo's own backend to be left out of pg_stat_activity but got %s", queryor.query)
+	}
+}
+
+// snapshotQueryor returns a single snapshot row with the given JSON rows of the views.
+type snapshotQueryor struct {
+	capturedAt time.Time
+	views      []string
+	query      string
+}
+
+func (q *snapshotQueryor) QueryRows(ctx context.Context, query string, args ...interface{}) (pogo.Rows, error) {
+	q.query = query
+	return &snapshotRows{queryor: q}, nil
+}
+
+type snapshotRows struct {
+	queryor *snapshotQueryor
+	read    bool
+}
+
+func (r *snapshotRows) Next() bool {
+	if r.read {
+		return false
+	}
+	r.read = true
+	return true
+}
+
+func (r *snapshotRows) Scan(dest ...interface{}) error {
+	*dest[0].(*time.Time) = r.queryor.capturedAt
+	for i, view := range r.queryor.views {
+		if err := dest[i+1].(sql.Scanner).Scan([]byte(view)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *snapshotRows) Err() error   { return nil }
+func (r *snapshotRows) Close() error { return nil }
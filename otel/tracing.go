@@ -0,0 +1,60 @@
+package pogootel
+
+import (
+	"context"
+	"time"
+
+	"github.com/sanggonlee/pogo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/sanggonlee/pogo/otel"
+
+// Attribute keys set on the spans of the queries.
+const (
+	AttributeQueryable = attribute.Key("pogo.queryable")
+	AttributeRows      = attribute.Key("pogo.rows")
+)
+
+// TracingHooks are pogo.Hooks tracing every query pogo runs in a span.
+// The spans hold the SQL and the description of the queryable tree the
+// query was generated from, along with the number of rows read.
+type TracingHooks struct {
+	tracer trace.Tracer
+}
+
+// NewTracingHooks returns hooks tracing with the tracer provider, or the
+// global one if nil.
+func NewTracingHooks(tp trace.TracerProvider) TracingHooks {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return TracingHooks{tracer: tp.Tracer(instrumentationName)}
+}
+
+// BeforeQuery starts the span of the query.
+func (h TracingHooks) BeforeQuery(ctx context.Context, sql string, args []interface{}) context.Context {
+	ctx, _ = h.tracer.Start(ctx, "pogo.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", sql),
+			AttributeQueryable.String(pogo.QueryDescriptionFromContext(ctx)),
+		),
+	)
+	return ctx
+}
+
+// AfterQuery ends the span of the query, recording its error if any.
+func (h TracingHooks) AfterQuery(ctx context.Context, sql string, duration time.Duration, rows int, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(AttributeRows.Int(rows))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
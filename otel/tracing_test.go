@@ -0,0 +1,38 @@
+package pogootel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pogootel "github.com/sanggonlee/pogo/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingHooks(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	hooks := pogootel.NewTracingHooks(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx := hooks.BeforeQuery(context.Background(), "SELECT 1", nil)
+	hooks.AfterQuery(ctx, "SELECT 1", time.Millisecond, 0, errors.New("canceled"))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 ended span but got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected error status but got %v", spans[0].Status())
+	}
+	found := false
+	for _, a := range spans[0].Attributes() {
+		if a.Key == "db.statement" && a.Value.AsString() == "SELECT 1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected db.statement attribute but got %v", spans[0].Attributes())
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/sanggonlee/pogo/pgxadapter
+
+go 1.15
+
+require (
+	github.com/jackc/pgx/v4 v4.18.1
+	github.com/sanggonlee/pogo v0.2.0
+)
+
+// Build against the pogo in this repository until v0.2.0 is tagged.
+replace github.com/sanggonlee/pogo => ../
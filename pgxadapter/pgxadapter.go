@@ -0,0 +1,64 @@
+// Package pgxadapter runs pogo queries on pgx connections, pools and
+// transactions, without going through the database/sql shim. The rows are
+// scanned with pgx natively, and the convenience methods work as they are:
+//  statActivities, err := pgxadapter.Query(pool).StatActivity13("", pogo.LocksView)
+package pgxadapter
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sanggonlee/pogo"
+)
+
+// Querier is an interface for querying with pgx.
+// pgx.Conn, pgxpool.Pool, pgxpool.Conn and pgx.Tx implement this.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// Adapter adapts a pgx Querier to pogo.RowsQueryor.
+type Adapter struct {
+	querier Querier
+}
+
+// New returns an Adapter running the queries with querier.
+func New(querier Querier) *Adapter {
+	return &Adapter{querier: querier}
+}
+
+// Query prepares a pogo query running instance on querier.
+func Query(querier Querier) pogo.QueryRunner {
+	return pogo.QueryWith(New(querier))
+}
+
+// QueryContext is like Query, except it also takes a context.
+func QueryContext(ctx context.Context, querier Querier) pogo.QueryRunner {
+	return pogo.QueryWithContext(ctx, New(querier))
+}
+
+// QueryRows runs the query with pgx. A nil context is replaced with
+// context.Background, as pgx requires one.
+func (a *Adapter) QueryRows(ctx context.Context, query string, args ...interface{}) (pogo.Rows, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rows, err := a.querier.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return adaptedRows{rows}, nil
+}
+
+// adaptedRows adapts pgx.Rows to pogo.Rows.
+type adaptedRows struct {
+	pgx.Rows
+}
+
+// Close closes the rows and returns the error of the iteration, if any,
+// since pgx.Rows.Close doesn't return one.
+func (r adaptedRows) Close() error {
+	r.Rows.Close()
+	return r.Rows.Err()
+}
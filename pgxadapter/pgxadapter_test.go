@@ -0,0 +1,115 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/sanggonlee/pogo"
+	"github.com/sanggonlee/pogo/pgxadapter"
+	"github.com/sanggonlee/pogo/postgres13"
+)
+
+func TestQuery(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	q := &fakeQuerier{rows: &fakeRows{values: [][]interface{}{
+		{int64(42), "active"},
+		{int64(43), "idle"},
+	}}}
+	queryable := pogo.StatActivityView.Columns("pid", "state")
+
+	rows, err := pgxadapter.Query(q).Rows(queryable)
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	var ss []postgres13.StatActivityJoined
+	for rows.Next() {
+		var s postgres13.StatActivityJoined
+		if err := rows.Scan(queryable.ScanDestinations(&s)...); err != nil {
+			t.Fatalf("Expected nil error but got %v", err)
+		}
+		ss = append(ss, s)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	if q.ctx == nil {
+		t.Error("Expected the query to run with a context")
+	}
+	if !strings.Contains(q.query, "pg_stat_activity.pid, pg_stat_activity.state") {
+		t.Errorf("Expected the selected columns in the query but got %s", q.query)
+	}
+	if len(ss) != 2 || ss[0].PID.Int64 != 42 || ss[1].State.String != "idle" {
+		t.Errorf("Expected the rows to be scanned but got %+v", ss)
+	}
+	if !q.rows.closed {
+		t.Error("Expected the pgx rows to be closed")
+	}
+}
+
+func TestQuery_CloseReturnsIterationError(t *testing.T) {
+	iterationErr := errors.New("conn closed")
+	q := &fakeQuerier{rows: &fakeRows{err: iterationErr}}
+
+	rows, err := pgxadapter.New(q).QueryRows(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	for rows.Next() {
+	}
+	if err := rows.Close(); err != iterationErr {
+		t.Errorf("Expected error %v but got %v", iterationErr, err)
+	}
+}
+
+type fakeQuerier struct {
+	ctx   context.Context
+	query string
+	rows  *fakeRows
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	q.ctx = ctx
+	q.query = sql
+	return q.rows, nil
+}
+
+// fakeRows returns the given rows of values. The methods of pgx.Rows not
+// used by the adapter are left unimplemented.
+type fakeRows struct {
+	pgx.Rows
+
+	values [][]interface{}
+	next   int
+	err    error
+	closed bool
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.next <= len(r.values)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	row := r.values[r.next-1]
+	for i, d := range dest {
+		scanner, ok := d.(sql.Scanner)
+		if !ok {
+			return errors.New("destination is not a sql.Scanner")
+		}
+		if err := scanner.Scan(row[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeRows) Err() error { return r.err }
+
+func (r *fakeRows) Close() { r.closed = true }
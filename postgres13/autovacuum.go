@@ -0,0 +1,225 @@
+package postgres13
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// AutovacuumSettings are the settings deciding when autovacuum processes a
+// table, read from pg_settings and overridden by the storage parameters of
+// each table.
+type AutovacuumSettings struct {
+	Autovacuum  bool          `json:"autovacuum"`
+	TrackCounts bool          `json:"track_counts"`
+	Naptime     time.Duration `json:"naptime"`
+	MaxWorkers  int64         `json:"max_workers"`
+
+	// TableDisabled is set when the table has autovacuum_enabled turned off.
+	TableDisabled bool `json:"table_disabled"`
+
+	VacuumThreshold         int64   `json:"vacuum_threshold"`
+	VacuumScaleFactor       float64 `json:"vacuum_scale_factor"`
+	VacuumInsertThreshold   int64   `json:"vacuum_insert_threshold"`
+	VacuumInsertScaleFactor float64 `json:"vacuum_insert_scale_factor"`
+	AnalyzeThreshold        int64   `json:"analyze_threshold"`
+	AnalyzeScaleFactor      float64 `json:"analyze_scale_factor"`
+}
+
+// Set sets the setting of the given name in pg_settings, or the storage
+// parameter of the given name, to value. Other names are ignored.
+func (s *AutovacuumSettings) Set(name, value string) error {
+	var err error
+	switch name {
+	case "autovacuum":
+		s.Autovacuum, err = parseBool(value)
+	case "track_counts":
+		s.TrackCounts, err = parseBool(value)
+	case "autovacuum_enabled":
+		var enabled bool
+		enabled, err = parseBool(value)
+		s.TableDisabled = !enabled
+	case "autovacuum_naptime":
+		var seconds int64
+		seconds, err = strconv.ParseInt(value, 10, 64)
+		s.Naptime = time.Duration(seconds) * time.Second
+	case "autovacuum_max_workers":
+		s.MaxWorkers, err = strconv.ParseInt(value, 10, 64)
+	case "autovacuum_vacuum_threshold":
+		s.VacuumThreshold, err = strconv.ParseInt(value, 10, 64)
+	case "autovacuum_vacuum_scale_factor":
+		s.VacuumScaleFactor, err = strconv.ParseFloat(value, 64)
+	case "autovacuum_vacuum_insert_threshold":
+		s.VacuumInsertThreshold, err = strconv.ParseInt(value, 10, 64)
+	case "autovacuum_vacuum_insert_scale_factor":
+		s.VacuumInsertScaleFactor, err = strconv.ParseFloat(value, 64)
+	case "autovacuum_analyze_threshold":
+		s.AnalyzeThreshold, err = strconv.ParseInt(value, 10, 64)
+	case "autovacuum_analyze_scale_factor":
+		s.AnalyzeScaleFactor, err = strconv.ParseFloat(value, 64)
+	}
+	return errors.Wrapf(err, "parsing %s", name)
+}
+
+// Enabled reports whether autovacuum processes the table when it's past its
+// thresholds: autovacuum and track_counts are on, and autovacuum_enabled isn't
+// turned off for the table. It's still vacuumed to prevent wraparound otherwise.
+func (s AutovacuumSettings) Enabled() bool {
+	return s.Autovacuum && s.TrackCounts && !s.TableDisabled
+}
+
+// ForTable returns the settings overridden by the storage parameters of a
+// table, given as in pg_class.reloptions, e.g. autovacuum_vacuum_scale_factor=0.01.
+func (s AutovacuumSettings) ForTable(reloptions []string) (AutovacuumSettings, error) {
+	for _, o := range reloptions {
+		name, value := o, ""
+		if i := strings.Index(o, "="); i >= 0 {
+			name, value = o[:i], o[i+1:]
+		}
+		if name == "autovacuum" || name == "track_counts" {
+			continue
+		}
+		if err := s.Set(name, value); err != nil {
+			return AutovacuumSettings{}, err
+		}
+	}
+	return s, nil
+}
+
+// parseBool parses a boolean the way Postgres accepts them in settings.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "yes":
+		return true, nil
+	case "off", "no":
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// TableAutovacuum is a row in pg_stat_user_tables along with the settings
+// autovacuum applies to the table, and its estimated number of rows and
+// storage parameters from pg_class, from which its thresholds are computed.
+type TableAutovacuum struct {
+	StatTable
+	RelTuples  null.Float         `json:"reltuples"`
+	RelOptions pq.StringArray     `json:"reloptions"`
+	Settings   AutovacuumSettings `json:"settings"`
+}
+
+// Selects returns the column names for select query, the ones of
+// pg_stat_user_tables followed by the ones of pg_class.
+func (t *TableAutovacuum) Selects() []string {
+	return append(t.StatTable.Selects(), "reltuples", "reloptions")
+}
+
+// Expressions returns the expressions reading the columns of pg_class.
+func (t *TableAutovacuum) Expressions() map[string]string {
+	return map[string]string{
+		"reltuples":  "(SELECT reltuples FROM pg_class WHERE pg_class.oid = pg_stat_user_tables.relid)",
+		"reloptions": "(SELECT reloptions FROM pg_class WHERE pg_class.oid = pg_stat_user_tables.relid)",
+	}
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (t *TableAutovacuum) ScanDestinations(joins []query.Queryable) []interface{} {
+	return append(t.StatTable.scanDestinations(), &t.RelTuples, &t.RelOptions)
+}
+
+func (t *TableAutovacuum) relTuples() float64 {
+	return math.Max(t.RelTuples.Float64, 0)
+}
+
+// VacuumThreshold returns the number of dead rows above which autovacuum
+// vacuums the table.
+func (t *TableAutovacuum) VacuumThreshold() float64 {
+	return float64(t.Settings.VacuumThreshold) + t.Settings.VacuumScaleFactor*t.relTuples()
+}
+
+// VacuumInsertThreshold returns the number of rows inserted since the last
+// vacuum above which autovacuum vacuums the table. It's null if autovacuum
+// isn't triggered by inserts for the table.
+func (t *TableAutovacuum) VacuumInsertThreshold() null.Float {
+	if t.Settings.VacuumInsertThreshold < 0 {
+		return null.Float{}
+	}
+	return null.FloatFrom(float64(t.Settings.VacuumInsertThreshold) + t.Settings.VacuumInsertScaleFactor*t.relTuples())
+}
+
+// AnalyzeThreshold returns the number of rows modified since the last analyze
+// above which autovacuum analyzes the table.
+func (t *TableAutovacuum) AnalyzeThreshold() float64 {
+	return float64(t.Settings.AnalyzeThreshold) + t.Settings.AnalyzeScaleFactor*t.relTuples()
+}
+
+// DeadRowsUntilVacuum returns the number of rows left to be deleted or updated
+// before autovacuum vacuums the table. It's negative once it's past due.
+func (t *TableAutovacuum) DeadRowsUntilVacuum() int64 {
+	return int64(math.Floor(t.VacuumThreshold())) - t.NumEstimatedDeadRows.Int64
+}
+
+// InsertsUntilVacuum returns the number of rows left to be inserted before
+// autovacuum vacuums the table. It's negative once it's past due, and null if
+// autovacuum isn't triggered by inserts for the table.
+func (t *TableAutovacuum) InsertsUntilVacuum() null.Int {
+	threshold := t.VacuumInsertThreshold()
+	if !threshold.Valid {
+		return null.Int{}
+	}
+	return null.IntFrom(int64(math.Floor(threshold.Float64)) - t.NumInsertsSinceVacuum.Int64)
+}
+
+// ModifiedRowsUntilAnalyze returns the number of rows left to be modified
+// before autovacuum analyzes the table. It's negative once it's past due.
+func (t *TableAutovacuum) ModifiedRowsUntilAnalyze() int64 {
+	return int64(math.Floor(t.AnalyzeThreshold())) - t.NumRowsModifiedSinceAnalyze.Int64
+}
+
+// VacuumOverdue reports whether the table is past any of its vacuum thresholds,
+// so autovacuum should be vacuuming it on its next visit of the database.
+func (t *TableAutovacuum) VacuumOverdue() bool {
+	inserts := t.InsertsUntilVacuum()
+	return t.DeadRowsUntilVacuum() < 0 || (inserts.Valid && inserts.Int64 < 0)
+}
+
+// AnalyzeOverdue reports whether the table is past its analyze threshold.
+func (t *TableAutovacuum) AnalyzeOverdue() bool {
+	return t.ModifiedRowsUntilAnalyze() < 0
+}
+
+// AutovacuumHealth is the state of autovacuum in the current database: the
+// settings, the user tables with their thresholds and the workers running.
+type AutovacuumHealth struct {
+	Settings AutovacuumSettings `json:"settings"`
+	Tables   []TableAutovacuum  `json:"tables"`
+	Workers  StatActivities     `json:"workers"`
+}
+
+// OverdueTables returns the tables past their vacuum or analyze thresholds,
+// which autovacuum processes on its next visit of the database.
+func (h AutovacuumHealth) OverdueTables() []TableAutovacuum {
+	return h.overdueTables(true)
+}
+
+// DisabledOverdueTables returns the tables past their vacuum or analyze
+// thresholds that autovacuum won't process, since it's disabled for them.
+// They're only vacuumed once they're at risk of wraparound.
+func (h AutovacuumHealth) DisabledOverdueTables() []TableAutovacuum {
+	return h.overdueTables(false)
+}
+
+func (h AutovacuumHealth) overdueTables(enabled bool) []TableAutovacuum {
+	overdue := make([]TableAutovacuum, 0)
+	for _, t := range h.Tables {
+		if t.Settings.Enabled() == enabled && (t.VacuumOverdue() || t.AnalyzeOverdue()) {
+			overdue = append(overdue, t)
+		}
+	}
+	return overdue
+}
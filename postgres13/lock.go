@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 
 	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
 	"github.com/sanggonlee/pogo/internal/query"
 	"gopkg.in/guregu/null.v3"
 )
@@ -30,21 +31,21 @@ type Lock struct {
 // Selects returns the column names for select query.
 func (l *Lock) Selects() []string {
 	return []string{
-		"pg_locks.locktype",
-		"pg_locks.database",
-		"pg_locks.relation",
-		"pg_locks.page",
-		"pg_locks.tuple",
-		"pg_locks.virtualxid",
-		"pg_locks.transactionid",
-		"pg_locks.classid",
-		"pg_locks.objid",
-		"pg_locks.objsubid",
-		"pg_locks.virtualtransaction",
-		"pg_locks.pid",
-		"pg_locks.mode",
-		"pg_locks.granted",
-		"pg_locks.fastpath",
+		"locktype",
+		"database",
+		"relation",
+		"page",
+		"tuple",
+		"virtualxid",
+		"transactionid",
+		"classid",
+		"objid",
+		"objsubid",
+		"virtualtransaction",
+		"pid",
+		"mode",
+		"granted",
+		"fastpath",
 	}
 }
 
@@ -63,6 +64,14 @@ type LockJoined struct {
 	IndexesIO   StatIOIndexes   `json:"indexes_io"`
 	SequencesIO StatIOSequences `json:"sequences_io"`
 	LockedRow   null.String     `json:"locked_row"`
+
+	ActivitiesAggregate  pginternal.Aggregate `json:"activities_aggregate"`
+	DatabasesAggregate   pginternal.Aggregate `json:"databases_aggregate"`
+	TablesAggregate      pginternal.Aggregate `json:"tables_aggregate"`
+	IndexesAggregate     pginternal.Aggregate `json:"indexes_aggregate"`
+	TablesIOAggregate    pginternal.Aggregate `json:"tables_io_aggregate"`
+	IndexesIOAggregate   pginternal.Aggregate `json:"indexes_io_aggregate"`
+	SequencesIOAggregate pginternal.Aggregate `json:"sequences_io_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -89,19 +98,19 @@ func (lj *LockJoined) ScanDestinations(joins []query.Queryable) []interface{} {
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetStatActivity:
-			joinDest = &lj.Activities
+			joinDest = j.Destination(&lj.Activities, &lj.ActivitiesAggregate)
 		case query.TargetStatDatabase:
-			joinDest = &lj.Databases
+			joinDest = j.Destination(&lj.Databases, &lj.DatabasesAggregate)
 		case query.TargetStatUserTables:
-			joinDest = &lj.Tables
+			joinDest = j.Destination(&lj.Tables, &lj.TablesAggregate)
 		case query.TargetStatUserIndexes:
-			joinDest = &lj.Indexes
+			joinDest = j.Destination(&lj.Indexes, &lj.IndexesAggregate)
 		case query.TargetStatIOUserTables:
-			joinDest = &lj.TablesIO
+			joinDest = j.Destination(&lj.TablesIO, &lj.TablesIOAggregate)
 		case query.TargetStatIOUserIndexes:
-			joinDest = &lj.IndexesIO
+			joinDest = j.Destination(&lj.IndexesIO, &lj.IndexesIOAggregate)
 		case query.TargetStatIOUserSequences:
-			joinDest = &lj.SequencesIO
+			joinDest = j.Destination(&lj.SequencesIO, &lj.SequencesIOAggregate)
 		}
 		dests = append(dests, joinDest)
 	}
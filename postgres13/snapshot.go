@@ -0,0 +1,109 @@
+package postgres13
+
+import (
+	"time"
+
+	"github.com/sanggonlee/pogo/internal/query"
+)
+
+// Snapshot holds the rows of several views read at the same point in time.
+// Only the views asked for are set.
+type Snapshot struct {
+	CapturedAt time.Time `json:"captured_at"`
+
+	Locks                     Locks                     `json:"locks,omitempty"`
+	StatActivities            StatActivities            `json:"stat_activities,omitempty"`
+	StatReplications          StatReplications          `json:"stat_replications,omitempty"`
+	StatSSLs                  StatSSLs                  `json:"stat_ssls,omitempty"`
+	StatGSSAPIs               StatGSSAPIs               `json:"stat_gssapis,omitempty"`
+	StatWALReceivers          StatWALReceivers          `json:"stat_wal_receivers,omitempty"`
+	StatSubscriptions         StatSubscriptions         `json:"stat_subscriptions,omitempty"`
+	StatDatabases             StatDatabases             `json:"stat_databases,omitempty"`
+	StatDatabaseConflicts     StatDatabaseConflicts     `json:"stat_database_conflicts,omitempty"`
+	StatTables                StatTables                `json:"stat_tables,omitempty"`
+	StatIndexes               StatIndexes               `json:"stat_indexes,omitempty"`
+	StatIOTables              StatIOTables              `json:"statio_tables,omitempty"`
+	StatIOIndexes             StatIOIndexes             `json:"statio_indexes,omitempty"`
+	StatIOSequences           StatIOSequences           `json:"statio_sequences,omitempty"`
+	StatUserFunctions         StatUserFunctions         `json:"stat_user_functions,omitempty"`
+	StatArchivers             StatArchivers             `json:"stat_archivers,omitempty"`
+	StatBGWriters             StatBGWriters             `json:"stat_bgwriters,omitempty"`
+	StatSLRUs                 StatSLRUs                 `json:"stat_slrus,omitempty"`
+	StatStatements            StatStatements            `json:"stat_statements,omitempty"`
+	StatProgressVacuums       StatProgressVacuums       `json:"stat_progress_vacuums,omitempty"`
+	StatProgressAnalyzes      StatProgressAnalyzes      `json:"stat_progress_analyzes,omitempty"`
+	StatProgressCreateIndexes StatProgressCreateIndexes `json:"stat_progress_create_indexes,omitempty"`
+	StatProgressClusters      StatProgressClusters      `json:"stat_progress_clusters,omitempty"`
+	StatProgressBasebackups   StatProgressBasebackups   `json:"stat_progress_basebackups,omitempty"`
+	ReplicationSlots          ReplicationSlots          `json:"replication_slots,omitempty"`
+	PreparedXacts             PreparedXacts             `json:"prepared_xacts,omitempty"`
+}
+
+// ScanDestinations returns the destinations for scanning the snapshot row in struct fields.
+// The destination is nil for the views that can't be included in a snapshot.
+func (s *Snapshot) ScanDestinations(views []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&s.CapturedAt,
+	}
+
+	for _, v := range views {
+		var dest interface{}
+		switch v.Target {
+		case query.TargetLocks:
+			dest = &s.Locks
+		case query.TargetStatActivity:
+			dest = &s.StatActivities
+		case query.TargetStatReplication:
+			dest = &s.StatReplications
+		case query.TargetStatSSL:
+			dest = &s.StatSSLs
+		case query.TargetStatGSSAPI:
+			dest = &s.StatGSSAPIs
+		case query.TargetStatWALReceiver:
+			dest = &s.StatWALReceivers
+		case query.TargetStatSubscription:
+			dest = &s.StatSubscriptions
+		case query.TargetStatDatabase:
+			dest = &s.StatDatabases
+		case query.TargetStatDatabaseConflicts:
+			dest = &s.StatDatabaseConflicts
+		case query.TargetStatUserTables:
+			dest = &s.StatTables
+		case query.TargetStatUserIndexes:
+			dest = &s.StatIndexes
+		case query.TargetStatIOUserTables:
+			dest = &s.StatIOTables
+		case query.TargetStatIOUserIndexes:
+			dest = &s.StatIOIndexes
+		case query.TargetStatIOUserSequences:
+			dest = &s.StatIOSequences
+		case query.TargetStatUserFunctions:
+			dest = &s.StatUserFunctions
+		case query.TargetStatArchiver:
+			dest = &s.StatArchivers
+		case query.TargetStatBGWriter:
+			dest = &s.StatBGWriters
+		case query.TargetStatSLRU:
+			dest = &s.StatSLRUs
+		case query.TargetStatStatements:
+			dest = &s.StatStatements
+		case query.TargetStatProgressVacuum:
+			dest = &s.StatProgressVacuums
+		case query.TargetStatProgressAnalyze:
+			dest = &s.StatProgressAnalyzes
+		case query.TargetStatProgressCreateIndex:
+			dest = &s.StatProgressCreateIndexes
+		case query.TargetStatProgressCluster:
+			dest = &s.StatProgressClusters
+		case query.TargetStatProgressBasebackup:
+			dest = &s.StatProgressBasebackups
+		case query.TargetReplicationSlots:
+			dest = &s.ReplicationSlots
+		case query.TargetPreparedXacts:
+			dest = &s.PreparedXacts
+		}
+		dests = append(dests, dest)
+	}
+
+	return dests
+}
@@ -27,17 +27,17 @@ type StatBGWriter struct {
 // Selects returns the column names for select query.
 func (s *StatBGWriter) Selects() []string {
 	return []string{
-		"pg_stat_bgwriter.checkpoints_timed",
-		"pg_stat_bgwriter.checkpoints_req",
-		"pg_stat_bgwriter.checkpoint_write_time",
-		"pg_stat_bgwriter.checkpoint_sync_time",
-		"pg_stat_bgwriter.buffers_checkpoint",
-		"pg_stat_bgwriter.buffers_clean",
-		"pg_stat_bgwriter.maxwritten_clean",
-		"pg_stat_bgwriter.buffers_backend",
-		"pg_stat_bgwriter.buffers_backend_fsync",
-		"pg_stat_bgwriter.buffers_alloc",
-		"pg_stat_bgwriter.stats_reset",
+		"checkpoints_timed",
+		"checkpoints_req",
+		"checkpoint_write_time",
+		"checkpoint_sync_time",
+		"buffers_checkpoint",
+		"buffers_clean",
+		"maxwritten_clean",
+		"buffers_backend",
+		"buffers_backend_fsync",
+		"buffers_alloc",
+		"stats_reset",
 	}
 }
 
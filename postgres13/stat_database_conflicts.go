@@ -24,13 +24,13 @@ type StatDatabaseConflict struct {
 // Selects returns the column names for select query.
 func (s *StatDatabaseConflict) Selects() []string {
 	return []string{
-		"pg_stat_database_conflicts.datid",
-		"pg_stat_database_conflicts.datname",
-		"pg_stat_database_conflicts.confl_tablespace",
-		"pg_stat_database_conflicts.confl_lock",
-		"pg_stat_database_conflicts.confl_snapshot",
-		"pg_stat_database_conflicts.confl_bufferpin",
-		"pg_stat_database_conflicts.confl_deadlock",
+		"datid",
+		"datname",
+		"confl_tablespace",
+		"confl_lock",
+		"confl_snapshot",
+		"confl_bufferpin",
+		"confl_deadlock",
 	}
 }
 
@@ -41,6 +41,10 @@ type StatDatabaseConflictJoined struct {
 	Conflicts  StatDatabaseConflicts `json:"conflicts"`
 	Locks      Locks                 `json:"locks"`
 	Activities StatActivities        `json:"activities"`
+
+	ConflictsAggregate  pginternal.Aggregate `json:"conflicts_aggregate"`
+	LocksAggregate      pginternal.Aggregate `json:"locks_aggregate"`
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -59,11 +63,11 @@ func (sj *StatDatabaseConflictJoined) ScanDestinations(joins []query.Queryable)
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetStatDatabaseConflicts:
-			joinDest = &sj.Conflicts
+			joinDest = j.Destination(&sj.Conflicts, &sj.ConflictsAggregate)
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatActivity:
-			joinDest = &sj.Activities
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
 		}
 		dests = append(dests, joinDest)
 	}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 
 	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
 	"github.com/sanggonlee/pogo/internal/query"
 	"gopkg.in/guregu/null.v3"
 )
@@ -19,10 +20,10 @@ type StatGSSAPI struct {
 // Selects returns the column names for select query.
 func (s *StatGSSAPI) Selects() []string {
 	return []string{
-		"pg_stat_gssapi.pid",
-		"pg_stat_gssapi.gss_authenticated",
-		"pg_stat_gssapi.principal",
-		"pg_stat_gssapi.encrypted",
+		"pid",
+		"gss_authenticated",
+		"principal",
+		"encrypted",
 	}
 }
 
@@ -32,6 +33,9 @@ type StatGSSAPIJoined struct {
 
 	Locks      Locks          `json:"locks"`
 	Activities StatActivities `json:"activities"`
+
+	LocksAggregate      pginternal.Aggregate `json:"locks_aggregate"`
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -47,9 +51,9 @@ func (sj *StatGSSAPIJoined) ScanDestinations(joins []query.Queryable) []interfac
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatActivity:
-			joinDest = &sj.Activities
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
 		}
 		dests = append(dests, joinDest)
 	}
@@ -0,0 +1,130 @@
+package postgres13
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatProgressAnalyze represents a row in pg_stat_progress_analyze view.
+type StatProgressAnalyze struct {
+	PID                    null.Int          `json:"pid,omitempty"`
+	DatID                  pginternal.OID    `json:"datid,omitempty"`
+	DatName                null.String       `json:"datname,omitempty"`
+	RelID                  pginternal.OID    `json:"relid,omitempty"`
+	Phase                  null.String       `json:"phase,omitempty"`
+	SampleBlocksTotal      pginternal.BigInt `json:"sample_blks_total,omitempty"`
+	SampleBlocksScanned    pginternal.BigInt `json:"sample_blks_scanned,omitempty"`
+	ExtStatsTotal          pginternal.BigInt `json:"ext_stats_total,omitempty"`
+	ExtStatsComputed       pginternal.BigInt `json:"ext_stats_computed,omitempty"`
+	ChildTablesTotal       pginternal.BigInt `json:"child_tables_total,omitempty"`
+	ChildTablesDone        pginternal.BigInt `json:"child_tables_done,omitempty"`
+	CurrentChildTableRelID pginternal.OID    `json:"current_child_table_relid,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatProgressAnalyze) Selects() []string {
+	return []string{
+		"pid",
+		"datid",
+		"datname",
+		"relid",
+		"phase",
+		"sample_blks_total",
+		"sample_blks_scanned",
+		"ext_stats_total",
+		"ext_stats_computed",
+		"child_tables_total",
+		"child_tables_done",
+		"current_child_table_relid",
+	}
+}
+
+// AnalyzePhase is a phase of an analyze, as reported in pg_stat_progress_analyze.
+type AnalyzePhase string
+
+// Analyze phases:
+const (
+	AnalyzePhaseInitializing                 AnalyzePhase = "initializing"
+	AnalyzePhaseAcquiringSampleRows          AnalyzePhase = "acquiring sample rows"
+	AnalyzePhaseAcquiringInheritedSampleRows AnalyzePhase = "acquiring inherited sample rows"
+	AnalyzePhaseComputingStatistics          AnalyzePhase = "computing statistics"
+	AnalyzePhaseComputingExtendedStatistics  AnalyzePhase = "computing extended statistics"
+	AnalyzePhaseFinalizingAnalyze            AnalyzePhase = "finalizing analyze"
+)
+
+// CurrentPhase returns the phase the analyze is in.
+func (s *StatProgressAnalyze) CurrentPhase() AnalyzePhase {
+	return AnalyzePhase(s.Phase.String)
+}
+
+// PercentComplete returns the percentage of the work done in the current phase:
+// the child tables scanned while acquiring inherited sample rows, the extended
+// statistics computed while computing them, and the sample blocks scanned otherwise.
+func (s *StatProgressAnalyze) PercentComplete() null.Float {
+	switch s.CurrentPhase() {
+	case AnalyzePhaseAcquiringInheritedSampleRows:
+		return pginternal.Percent(s.ChildTablesDone, s.ChildTablesTotal)
+	case AnalyzePhaseComputingExtendedStatistics:
+		return pginternal.Percent(s.ExtStatsComputed, s.ExtStatsTotal)
+	}
+	return pginternal.Percent(s.SampleBlocksScanned, s.SampleBlocksTotal)
+}
+
+// StatProgressAnalyzeJoined is the extended struct of StatProgressAnalyze with all the possible joinable fields.
+type StatProgressAnalyzeJoined struct {
+	StatProgressAnalyze
+
+	Activities StatActivities `json:"activities"`
+	Tables     StatTables     `json:"tables"`
+
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
+	TablesAggregate     pginternal.Aggregate `json:"tables_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatProgressAnalyzeJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.PID,
+		&sj.DatID,
+		&sj.DatName,
+		&sj.RelID,
+		&sj.Phase,
+		&sj.SampleBlocksTotal,
+		&sj.SampleBlocksScanned,
+		&sj.ExtStatsTotal,
+		&sj.ExtStatsComputed,
+		&sj.ChildTablesTotal,
+		&sj.ChildTablesDone,
+		&sj.CurrentChildTableRelID,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatActivity:
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
+		case query.TargetStatUserTables:
+			joinDest = j.Destination(&sj.Tables, &sj.TablesAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// StatProgressAnalyzes is an alias for a slice of StatProgressAnalyzeJoined.
+type StatProgressAnalyzes []StatProgressAnalyzeJoined
+
+// Scan reads the DB value into StatProgressAnalyzes.
+func (ss *StatProgressAnalyzes) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatProgressAnalyzes to a DB value.
+func (ss *StatProgressAnalyzes) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
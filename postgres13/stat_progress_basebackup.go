@@ -0,0 +1,102 @@
+package postgres13
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatProgressBasebackup represents a row in pg_stat_progress_basebackup view.
+// It has no relid, so it can only be joined with pg_stat_activity.
+type StatProgressBasebackup struct {
+	PID                 null.Int          `json:"pid,omitempty"`
+	Phase               null.String       `json:"phase,omitempty"`
+	BackupTotal         pginternal.BigInt `json:"backup_total,omitempty"`
+	BackupStreamed      pginternal.BigInt `json:"backup_streamed,omitempty"`
+	TablespacesTotal    pginternal.BigInt `json:"tablespaces_total,omitempty"`
+	TablespacesStreamed pginternal.BigInt `json:"tablespaces_streamed,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatProgressBasebackup) Selects() []string {
+	return []string{
+		"pid",
+		"phase",
+		"backup_total",
+		"backup_streamed",
+		"tablespaces_total",
+		"tablespaces_streamed",
+	}
+}
+
+// BasebackupPhase is a phase of a base backup, as reported in pg_stat_progress_basebackup.
+type BasebackupPhase string
+
+// Base backup phases:
+const (
+	BasebackupPhaseInitializing                   BasebackupPhase = "initializing"
+	BasebackupPhaseWaitingForCheckpointToFinish   BasebackupPhase = "waiting for checkpoint to finish"
+	BasebackupPhaseEstimatingBackupSize           BasebackupPhase = "estimating backup size"
+	BasebackupPhaseStreamingDatabaseFiles         BasebackupPhase = "streaming database files"
+	BasebackupPhaseWaitingForWALArchivingToFinish BasebackupPhase = "waiting for wal archiving to finish"
+	BasebackupPhaseTransferringWALFiles           BasebackupPhase = "transferring wal files"
+)
+
+// CurrentPhase returns the phase the base backup is in.
+func (s *StatProgressBasebackup) CurrentPhase() BasebackupPhase {
+	return BasebackupPhase(s.Phase.String)
+}
+
+// PercentComplete returns the percentage of the backup streamed so far. It's
+// null if the backup size isn't estimated, as with pg_basebackup --no-estimate-size.
+func (s *StatProgressBasebackup) PercentComplete() null.Float {
+	return pginternal.Percent(s.BackupStreamed, s.BackupTotal)
+}
+
+// StatProgressBasebackupJoined is the extended struct of StatProgressBasebackup with all the possible joinable fields.
+type StatProgressBasebackupJoined struct {
+	StatProgressBasebackup
+
+	Activities StatActivities `json:"activities"`
+
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatProgressBasebackupJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.PID,
+		&sj.Phase,
+		&sj.BackupTotal,
+		&sj.BackupStreamed,
+		&sj.TablespacesTotal,
+		&sj.TablespacesStreamed,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatActivity:
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// StatProgressBasebackups is an alias for a slice of StatProgressBasebackupJoined.
+type StatProgressBasebackups []StatProgressBasebackupJoined
+
+// Scan reads the DB value into StatProgressBasebackups.
+func (ss *StatProgressBasebackups) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatProgressBasebackups to a DB value.
+func (ss *StatProgressBasebackups) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
@@ -0,0 +1,133 @@
+package postgres13
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatProgressCluster represents a row in pg_stat_progress_cluster view.
+type StatProgressCluster struct {
+	PID               null.Int          `json:"pid,omitempty"`
+	DatID             pginternal.OID    `json:"datid,omitempty"`
+	DatName           null.String       `json:"datname,omitempty"`
+	RelID             pginternal.OID    `json:"relid,omitempty"`
+	Command           null.String       `json:"command,omitempty"`
+	Phase             null.String       `json:"phase,omitempty"`
+	ClusterIndexRelID pginternal.OID    `json:"cluster_index_relid,omitempty"`
+	HeapTuplesScanned pginternal.BigInt `json:"heap_tuples_scanned,omitempty"`
+	HeapTuplesWritten pginternal.BigInt `json:"heap_tuples_written,omitempty"`
+	HeapBlocksTotal   pginternal.BigInt `json:"heap_blks_total,omitempty"`
+	HeapBlocksScanned pginternal.BigInt `json:"heap_blks_scanned,omitempty"`
+	IndexRebuildCount pginternal.BigInt `json:"index_rebuild_count,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatProgressCluster) Selects() []string {
+	return []string{
+		"pid",
+		"datid",
+		"datname",
+		"relid",
+		"command",
+		"phase",
+		"cluster_index_relid",
+		"heap_tuples_scanned",
+		"heap_tuples_written",
+		"heap_blks_total",
+		"heap_blks_scanned",
+		"index_rebuild_count",
+	}
+}
+
+// ClusterPhase is a phase of a CLUSTER or VACUUM FULL, as reported in
+// pg_stat_progress_cluster.
+type ClusterPhase string
+
+// Cluster phases:
+const (
+	ClusterPhaseInitializing           ClusterPhase = "initializing"
+	ClusterPhaseSeqScanningHeap        ClusterPhase = "seq scanning heap"
+	ClusterPhaseIndexScanningHeap      ClusterPhase = "index scanning heap"
+	ClusterPhaseSortingTuples          ClusterPhase = "sorting tuples"
+	ClusterPhaseWritingNewHeap         ClusterPhase = "writing new heap"
+	ClusterPhaseSwappingRelationFiles  ClusterPhase = "swapping relation files"
+	ClusterPhaseRebuildingIndex        ClusterPhase = "rebuilding index"
+	ClusterPhasePerformingFinalCleanup ClusterPhase = "performing final cleanup"
+)
+
+// CurrentPhase returns the phase the cluster is in.
+func (s *StatProgressCluster) CurrentPhase() ClusterPhase {
+	return ClusterPhase(s.Phase.String)
+}
+
+// PercentComplete returns the percentage of the heap blocks scanned while
+// seq scanning the heap, and of the scanned tuples written while writing the
+// new heap. It's null in the other phases, which report no total.
+func (s *StatProgressCluster) PercentComplete() null.Float {
+	switch s.CurrentPhase() {
+	case ClusterPhaseSeqScanningHeap:
+		return pginternal.Percent(s.HeapBlocksScanned, s.HeapBlocksTotal)
+	case ClusterPhaseWritingNewHeap:
+		return pginternal.Percent(s.HeapTuplesWritten, s.HeapTuplesScanned)
+	}
+	return null.Float{}
+}
+
+// StatProgressClusterJoined is the extended struct of StatProgressCluster with all the possible joinable fields.
+type StatProgressClusterJoined struct {
+	StatProgressCluster
+
+	Activities StatActivities `json:"activities"`
+	Tables     StatTables     `json:"tables"`
+
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
+	TablesAggregate     pginternal.Aggregate `json:"tables_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatProgressClusterJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.PID,
+		&sj.DatID,
+		&sj.DatName,
+		&sj.RelID,
+		&sj.Command,
+		&sj.Phase,
+		&sj.ClusterIndexRelID,
+		&sj.HeapTuplesScanned,
+		&sj.HeapTuplesWritten,
+		&sj.HeapBlocksTotal,
+		&sj.HeapBlocksScanned,
+		&sj.IndexRebuildCount,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatActivity:
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
+		case query.TargetStatUserTables:
+			joinDest = j.Destination(&sj.Tables, &sj.TablesAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// StatProgressClusters is an alias for a slice of StatProgressClusterJoined.
+type StatProgressClusters []StatProgressClusterJoined
+
+// Scan reads the DB value into StatProgressClusters.
+func (ss *StatProgressClusters) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatProgressClusters to a DB value.
+func (ss *StatProgressClusters) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
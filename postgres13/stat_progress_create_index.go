@@ -0,0 +1,153 @@
+package postgres13
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatProgressCreateIndex represents a row in pg_stat_progress_create_index view.
+type StatProgressCreateIndex struct {
+	PID              null.Int          `json:"pid,omitempty"`
+	DatID            pginternal.OID    `json:"datid,omitempty"`
+	DatName          null.String       `json:"datname,omitempty"`
+	RelID            pginternal.OID    `json:"relid,omitempty"`
+	IndexRelID       pginternal.OID    `json:"index_relid,omitempty"`
+	Command          null.String       `json:"command,omitempty"`
+	Phase            null.String       `json:"phase,omitempty"`
+	LockersTotal     pginternal.BigInt `json:"lockers_total,omitempty"`
+	LockersDone      pginternal.BigInt `json:"lockers_done,omitempty"`
+	CurrentLockerPID pginternal.BigInt `json:"current_locker_pid,omitempty"`
+	BlocksTotal      pginternal.BigInt `json:"blocks_total,omitempty"`
+	BlocksDone       pginternal.BigInt `json:"blocks_done,omitempty"`
+	TuplesTotal      pginternal.BigInt `json:"tuples_total,omitempty"`
+	TuplesDone       pginternal.BigInt `json:"tuples_done,omitempty"`
+	PartitionsTotal  pginternal.BigInt `json:"partitions_total,omitempty"`
+	PartitionsDone   pginternal.BigInt `json:"partitions_done,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatProgressCreateIndex) Selects() []string {
+	return []string{
+		"pid",
+		"datid",
+		"datname",
+		"relid",
+		"index_relid",
+		"command",
+		"phase",
+		"lockers_total",
+		"lockers_done",
+		"current_locker_pid",
+		"blocks_total",
+		"blocks_done",
+		"tuples_total",
+		"tuples_done",
+		"partitions_total",
+		"partitions_done",
+	}
+}
+
+// CreateIndexPhase is a phase of a CREATE INDEX or REINDEX, as reported in
+// pg_stat_progress_create_index.
+type CreateIndexPhase string
+
+// Create index phases:
+const (
+	CreateIndexPhaseInitializing                       CreateIndexPhase = "initializing"
+	CreateIndexPhaseWaitingForWritersBeforeBuild       CreateIndexPhase = "waiting for writers before build"
+	CreateIndexPhaseBuildingIndex                      CreateIndexPhase = "building index"
+	CreateIndexPhaseWaitingForWritersBeforeValidation  CreateIndexPhase = "waiting for writers before validation"
+	CreateIndexPhaseIndexValidationScanningIndex       CreateIndexPhase = "index validation: scanning index"
+	CreateIndexPhaseIndexValidationSortingTuples       CreateIndexPhase = "index validation: sorting tuples"
+	CreateIndexPhaseIndexValidationScanningTable       CreateIndexPhase = "index validation: scanning table"
+	CreateIndexPhaseWaitingForOldSnapshots             CreateIndexPhase = "waiting for old snapshots"
+	CreateIndexPhaseWaitingForReadersBeforeMarkingDead CreateIndexPhase = "waiting for readers before marking dead"
+	CreateIndexPhaseWaitingForReadersBeforeDropping    CreateIndexPhase = "waiting for readers before dropping"
+)
+
+// CurrentPhase returns the phase the index build is in. The subphase of the
+// access method building the index, such as "building index: scanning table",
+// is left out; it's kept in Phase.
+func (s *StatProgressCreateIndex) CurrentPhase() CreateIndexPhase {
+	if strings.HasPrefix(s.Phase.String, string(CreateIndexPhaseBuildingIndex)) {
+		return CreateIndexPhaseBuildingIndex
+	}
+	return CreateIndexPhase(s.Phase.String)
+}
+
+// PercentComplete returns the percentage of the work done in the current phase:
+// the lockers waited for while waiting, and the blocks or else the tuples
+// processed otherwise.
+func (s *StatProgressCreateIndex) PercentComplete() null.Float {
+	if strings.HasPrefix(s.Phase.String, "waiting for") {
+		return pginternal.Percent(s.LockersDone, s.LockersTotal)
+	}
+	if s.BlocksTotal.Int64 > 0 {
+		return pginternal.Percent(s.BlocksDone, s.BlocksTotal)
+	}
+	return pginternal.Percent(s.TuplesDone, s.TuplesTotal)
+}
+
+// StatProgressCreateIndexJoined is the extended struct of StatProgressCreateIndex with all the possible joinable fields.
+type StatProgressCreateIndexJoined struct {
+	StatProgressCreateIndex
+
+	Activities StatActivities `json:"activities"`
+	Tables     StatTables     `json:"tables"`
+
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
+	TablesAggregate     pginternal.Aggregate `json:"tables_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatProgressCreateIndexJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.PID,
+		&sj.DatID,
+		&sj.DatName,
+		&sj.RelID,
+		&sj.IndexRelID,
+		&sj.Command,
+		&sj.Phase,
+		&sj.LockersTotal,
+		&sj.LockersDone,
+		&sj.CurrentLockerPID,
+		&sj.BlocksTotal,
+		&sj.BlocksDone,
+		&sj.TuplesTotal,
+		&sj.TuplesDone,
+		&sj.PartitionsTotal,
+		&sj.PartitionsDone,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatActivity:
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
+		case query.TargetStatUserTables:
+			joinDest = j.Destination(&sj.Tables, &sj.TablesAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// StatProgressCreateIndexes is an alias for a slice of StatProgressCreateIndexJoined.
+type StatProgressCreateIndexes []StatProgressCreateIndexJoined
+
+// Scan reads the DB value into StatProgressCreateIndexes.
+func (ss *StatProgressCreateIndexes) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatProgressCreateIndexes to a DB value.
+func (ss *StatProgressCreateIndexes) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
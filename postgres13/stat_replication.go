@@ -37,26 +37,26 @@ type StatReplication struct {
 // Selects returns the column names for select query.
 func (s *StatReplication) Selects() []string {
 	return []string{
-		"pg_stat_replication.pid",
-		"pg_stat_replication.usesysid",
-		"pg_stat_replication.usename",
-		"pg_stat_replication.application_name",
-		"pg_stat_replication.client_addr",
-		"pg_stat_replication.client_hostname",
-		"pg_stat_replication.client_port",
-		"pg_stat_replication.backend_start",
-		"pg_stat_replication.backend_xmin",
-		"pg_stat_replication.state",
-		"pg_stat_replication.sent_lsn",
-		"pg_stat_replication.write_lsn",
-		"pg_stat_replication.flush_lsn",
-		"pg_stat_replication.replay_lsn",
-		"pg_stat_replication.write_lag",
-		"pg_stat_replication.flush_lag",
-		"pg_stat_replication.replay_lag",
-		"pg_stat_replication.sync_priority",
-		"pg_stat_replication.sync_state",
-		"pg_stat_replication.reply_time",
+		"pid",
+		"usesysid",
+		"usename",
+		"application_name",
+		"client_addr",
+		"client_hostname",
+		"client_port",
+		"backend_start",
+		"backend_xmin",
+		"state",
+		"sent_lsn",
+		"write_lsn",
+		"flush_lsn",
+		"replay_lsn",
+		"write_lag",
+		"flush_lag",
+		"replay_lag",
+		"sync_priority",
+		"sync_state",
+		"reply_time",
 	}
 }
 
@@ -68,6 +68,13 @@ type StatReplicationJoined struct {
 	SSLUsages    StatSSLs         `json:"ssl_usages,omitempty"`
 	GSSAPIUsages StatGSSAPIs      `json:"gssapi_usages,omitempty"`
 	WalRecivers  StatWALReceivers `json:"wal_receivers,omitempty"`
+
+	ReplayLagBytes pginternal.BigInt `json:"replay_lag_bytes,omitempty"`
+
+	LocksAggregate        pginternal.Aggregate `json:"locks_aggregate,omitempty"`
+	SSLUsagesAggregate    pginternal.Aggregate `json:"ssl_usages_aggregate,omitempty"`
+	GSSAPIUsagesAggregate pginternal.Aggregate `json:"gssapi_usages_aggregate,omitempty"`
+	WalReciversAggregate  pginternal.Aggregate `json:"wal_receivers_aggregate,omitempty"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -99,13 +106,15 @@ func (sj *StatReplicationJoined) ScanDestinations(joins []query.Queryable) []int
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatSSL:
-			joinDest = &sj.SSLUsages
+			joinDest = j.Destination(&sj.SSLUsages, &sj.SSLUsagesAggregate)
 		case query.TargetStatGSSAPI:
-			joinDest = &sj.GSSAPIUsages
+			joinDest = j.Destination(&sj.GSSAPIUsages, &sj.GSSAPIUsagesAggregate)
 		case query.TargetStatWALReceiver:
-			joinDest = &sj.WalRecivers
+			joinDest = j.Destination(&sj.WalRecivers, &sj.WalReciversAggregate)
+		case query.TargetWALLSNDiff:
+			joinDest = &sj.ReplayLagBytes
 		}
 		dests = append(dests, joinDest)
 	}
@@ -25,15 +25,15 @@ type StatSLRU struct {
 // Selects returns the column names for select query.
 func (s *StatSLRU) Selects() []string {
 	return []string{
-		"pg_stat_slru.name",
-		"pg_stat_slru.blks_zeroed",
-		"pg_stat_slru.blks_hit",
-		"pg_stat_slru.blks_read",
-		"pg_stat_slru.blks_written",
-		"pg_stat_slru.blks_exists",
-		"pg_stat_slru.flushes",
-		"pg_stat_slru.truncates",
-		"pg_stat_slru.stats_reset",
+		"name",
+		"blks_zeroed",
+		"blks_hit",
+		"blks_read",
+		"blks_written",
+		"blks_exists",
+		"flushes",
+		"truncates",
+		"stats_reset",
 	}
 }
 
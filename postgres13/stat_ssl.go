@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 
 	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
 	"github.com/sanggonlee/pogo/internal/query"
 	"gopkg.in/guregu/null.v3"
 )
@@ -24,15 +25,15 @@ type StatSSL struct {
 // Selects returns the column names for select query.
 func (s *StatSSL) Selects() []string {
 	return []string{
-		"pg_stat_ssl.pid",
-		"pg_stat_ssl.ssl",
-		"pg_stat_ssl.version",
-		"pg_stat_ssl.cipher",
-		"pg_stat_ssl.bits",
-		"pg_stat_ssl.compression",
-		"pg_stat_ssl.client_dn",
-		"pg_stat_ssl.client_serial",
-		"pg_stat_ssl.issuer_dn",
+		"pid",
+		"ssl",
+		"version",
+		"cipher",
+		"bits",
+		"compression",
+		"client_dn",
+		"client_serial",
+		"issuer_dn",
 	}
 }
 
@@ -42,6 +43,9 @@ type StatSSLJoined struct {
 
 	Locks      Locks          `json:"locks"`
 	Activities StatActivities `json:"activities"`
+
+	LocksAggregate      pginternal.Aggregate `json:"locks_aggregate"`
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -62,9 +66,9 @@ func (sj *StatSSLJoined) ScanDestinations(joins []query.Queryable) []interface{}
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatActivity:
-			joinDest = &sj.Activities
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
 		}
 		dests = append(dests, joinDest)
 	}
@@ -0,0 +1,158 @@
+package postgres13
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatStatement represents a row in pg_stat_statements view of the
+// pg_stat_statements extension, as of its version 1.8 shipped with Postgres 13.
+// The execution times are split from the planning times, which are only
+// tracked with pg_stat_statements.track_planning on.
+type StatStatement struct {
+	UserID              pginternal.OID    `json:"userid,omitempty"`
+	DBID                pginternal.OID    `json:"dbid,omitempty"`
+	QueryID             pginternal.BigInt `json:"queryid,omitempty"`
+	Query               null.String       `json:"query,omitempty"`
+	Plans               pginternal.BigInt `json:"plans,omitempty"`
+	TotalPlanTime       null.Float        `json:"total_plan_time,omitempty"`
+	MinPlanTime         null.Float        `json:"min_plan_time,omitempty"`
+	MaxPlanTime         null.Float        `json:"max_plan_time,omitempty"`
+	MeanPlanTime        null.Float        `json:"mean_plan_time,omitempty"`
+	StddevPlanTime      null.Float        `json:"stddev_plan_time,omitempty"`
+	Calls               pginternal.BigInt `json:"calls,omitempty"`
+	TotalExecTime       null.Float        `json:"total_exec_time,omitempty"`
+	MinExecTime         null.Float        `json:"min_exec_time,omitempty"`
+	MaxExecTime         null.Float        `json:"max_exec_time,omitempty"`
+	MeanExecTime        null.Float        `json:"mean_exec_time,omitempty"`
+	StddevExecTime      null.Float        `json:"stddev_exec_time,omitempty"`
+	Rows                pginternal.BigInt `json:"rows,omitempty"`
+	SharedBlocksHit     pginternal.BigInt `json:"shared_blks_hit,omitempty"`
+	SharedBlocksRead    pginternal.BigInt `json:"shared_blks_read,omitempty"`
+	SharedBlocksDirtied pginternal.BigInt `json:"shared_blks_dirtied,omitempty"`
+	SharedBlocksWritten pginternal.BigInt `json:"shared_blks_written,omitempty"`
+	LocalBlocksHit      pginternal.BigInt `json:"local_blks_hit,omitempty"`
+	LocalBlocksRead     pginternal.BigInt `json:"local_blks_read,omitempty"`
+	LocalBlocksDirtied  pginternal.BigInt `json:"local_blks_dirtied,omitempty"`
+	LocalBlocksWritten  pginternal.BigInt `json:"local_blks_written,omitempty"`
+	TempBlocksRead      pginternal.BigInt `json:"temp_blks_read,omitempty"`
+	TempBlocksWritten   pginternal.BigInt `json:"temp_blks_written,omitempty"`
+	BlockReadTime       null.Float        `json:"blk_read_time,omitempty"`
+	BlockWriteTime      null.Float        `json:"blk_write_time,omitempty"`
+	WALRecords          pginternal.BigInt `json:"wal_records,omitempty"`
+	WALFPI              pginternal.BigInt `json:"wal_fpi,omitempty"`
+	WALBytes            pginternal.BigInt `json:"wal_bytes,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatStatement) Selects() []string {
+	return []string{
+		"userid",
+		"dbid",
+		"queryid",
+		"query",
+		"plans",
+		"total_plan_time",
+		"min_plan_time",
+		"max_plan_time",
+		"mean_plan_time",
+		"stddev_plan_time",
+		"calls",
+		"total_exec_time",
+		"min_exec_time",
+		"max_exec_time",
+		"mean_exec_time",
+		"stddev_exec_time",
+		"rows",
+		"shared_blks_hit",
+		"shared_blks_read",
+		"shared_blks_dirtied",
+		"shared_blks_written",
+		"local_blks_hit",
+		"local_blks_read",
+		"local_blks_dirtied",
+		"local_blks_written",
+		"temp_blks_read",
+		"temp_blks_written",
+		"blk_read_time",
+		"blk_write_time",
+		"wal_records",
+		"wal_fpi",
+		"wal_bytes",
+	}
+}
+
+// StatStatementJoined is the extended struct of StatStatement with all the possible joinable fields.
+type StatStatementJoined struct {
+	StatStatement
+
+	Databases StatDatabases `json:"databases"`
+
+	DatabasesAggregate pginternal.Aggregate `json:"databases_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatStatementJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.UserID,
+		&sj.DBID,
+		&sj.QueryID,
+		&sj.Query,
+		&sj.Plans,
+		&sj.TotalPlanTime,
+		&sj.MinPlanTime,
+		&sj.MaxPlanTime,
+		&sj.MeanPlanTime,
+		&sj.StddevPlanTime,
+		&sj.Calls,
+		&sj.TotalExecTime,
+		&sj.MinExecTime,
+		&sj.MaxExecTime,
+		&sj.MeanExecTime,
+		&sj.StddevExecTime,
+		&sj.Rows,
+		&sj.SharedBlocksHit,
+		&sj.SharedBlocksRead,
+		&sj.SharedBlocksDirtied,
+		&sj.SharedBlocksWritten,
+		&sj.LocalBlocksHit,
+		&sj.LocalBlocksRead,
+		&sj.LocalBlocksDirtied,
+		&sj.LocalBlocksWritten,
+		&sj.TempBlocksRead,
+		&sj.TempBlocksWritten,
+		&sj.BlockReadTime,
+		&sj.BlockWriteTime,
+		&sj.WALRecords,
+		&sj.WALFPI,
+		&sj.WALBytes,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatDatabase:
+			joinDest = j.Destination(&sj.Databases, &sj.DatabasesAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// StatStatements is an alias for a slice of StatStatementJoined.
+type StatStatements []StatStatementJoined
+
+// Scan reads the DB value into StatStatements.
+func (ss *StatStatements) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatStatements to a DB value.
+func (ss *StatStatements) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
@@ -25,15 +25,15 @@ type StatSubscription struct {
 // Selects returns the column names for select query.
 func (s *StatSubscription) Selects() []string {
 	return []string{
-		"pg_stat_subscription.subid",
-		"pg_stat_subscription.subname",
-		"pg_stat_subscription.pid",
-		"pg_stat_subscription.relid",
-		"pg_stat_subscription.received_lsn",
-		"pg_stat_subscription.last_msg_send_time",
-		"pg_stat_subscription.last_msg_receipt_time",
-		"pg_stat_subscription.latest_end_lsn",
-		"pg_stat_subscription.latest_end_time",
+		"subid",
+		"subname",
+		"pid",
+		"relid",
+		"received_lsn",
+		"last_msg_send_time",
+		"last_msg_receipt_time",
+		"latest_end_lsn",
+		"latest_end_time",
 	}
 }
 
@@ -43,6 +43,9 @@ type StatSubscriptionJoined struct {
 
 	Locks      Locks          `json:"locks"`
 	Activities StatActivities `json:"activities"`
+
+	LocksAggregate      pginternal.Aggregate `json:"locks_aggregate"`
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -63,9 +66,9 @@ func (sj *StatSubscriptionJoined) ScanDestinations(joins []query.Queryable) []in
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatActivity:
-			joinDest = &sj.Activities
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
 		}
 		dests = append(dests, joinDest)
 	}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 
 	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
 	"github.com/sanggonlee/pogo/internal/query"
 	"gopkg.in/guregu/null.v3"
 )
@@ -38,29 +39,59 @@ type StatTable struct {
 // Selects returns the column names for select query.
 func (s *StatTable) Selects() []string {
 	return []string{
-		"pg_stat_user_tables.relid",
-		"pg_stat_user_tables.schemaname",
-		"pg_stat_user_tables.relname",
-		"pg_stat_user_tables.seq_scan",
-		"pg_stat_user_tables.seq_tup_read",
-		"pg_stat_user_tables.idx_scan",
-		"pg_stat_user_tables.idx_tup_fetch",
-		"pg_stat_user_tables.n_tup_ins",
-		"pg_stat_user_tables.n_tup_upd",
-		"pg_stat_user_tables.n_tup_del",
-		"pg_stat_user_tables.n_tup_hot_upd",
-		"pg_stat_user_tables.n_live_tup",
-		"pg_stat_user_tables.n_dead_tup",
-		"pg_stat_user_tables.n_mod_since_analyze",
-		"pg_stat_user_tables.n_ins_since_vacuum",
-		"pg_stat_user_tables.vacuum_count",
-		"pg_stat_user_tables.last_vacuum",
-		"pg_stat_user_tables.autovacuum_count",
-		"pg_stat_user_tables.last_autovacuum",
-		"pg_stat_user_tables.analyze_count",
-		"pg_stat_user_tables.last_analyze",
-		"pg_stat_user_tables.autoanalyze_count",
-		"pg_stat_user_tables.last_autoanalyze",
+		"relid",
+		"schemaname",
+		"relname",
+		"seq_scan",
+		"seq_tup_read",
+		"idx_scan",
+		"idx_tup_fetch",
+		"n_tup_ins",
+		"n_tup_upd",
+		"n_tup_del",
+		"n_tup_hot_upd",
+		"n_live_tup",
+		"n_dead_tup",
+		"n_mod_since_analyze",
+		"n_ins_since_vacuum",
+		"vacuum_count",
+		"last_vacuum",
+		"autovacuum_count",
+		"last_autovacuum",
+		"analyze_count",
+		"last_analyze",
+		"autoanalyze_count",
+		"last_autoanalyze",
+	}
+}
+
+// scanDestinations returns the destinations for scanning the columns of Selects
+// in struct fields.
+func (s *StatTable) scanDestinations() []interface{} {
+	return []interface{}{
+		&s.RelID,
+		&s.SchemaName,
+		&s.RelName,
+		&s.NumSequentialScans,
+		&s.NumSequentialRowsRead,
+		&s.NumIndexScans,
+		&s.NumIndexRowsFetched,
+		&s.NumRowsInserted,
+		&s.NumRowsUpdated,
+		&s.NumRowsDeleted,
+		&s.NumRowsHotUpdated,
+		&s.NumEstimatedLiveRows,
+		&s.NumEstimatedDeadRows,
+		&s.NumRowsModifiedSinceAnalyze,
+		&s.NumInsertsSinceVacuum,
+		&s.NumManuallyVacuumed,
+		&s.LastManuallyVacuumedAt,
+		&s.NumAutoVacuumed,
+		&s.LastAutoVacuumedAt,
+		&s.NumManuallyAnalyzed,
+		&s.LastManuallyAnalyzedAt,
+		&s.NumAutoAnalyzed,
+		&s.LastAutoAnalyzedAt,
 	}
 }
 
@@ -73,51 +104,44 @@ type StatTableJoined struct {
 	IndexIOStats    StatIndexes       `json:"index_iostats"`
 	SequenceIOStats StatIOSequences   `json:"sequence_iostats"`
 	TableIOStats    StatIOTables      `json:"table_iostats"`
+
+	TotalRelationSize pginternal.BigInt `json:"total_relation_size"`
+	RelationSize      pginternal.BigInt `json:"relation_size"`
+	IndexesSize       pginternal.BigInt `json:"indexes_size"`
+
+	LocksAggregate           pginternal.Aggregate `json:"locks_aggregate"`
+	IndexesAggregate         pginternal.Aggregate `json:"indexes_aggregate"`
+	SubscriptionsAggregate   pginternal.Aggregate `json:"subscriptions_aggregate"`
+	IndexIOStatsAggregate    pginternal.Aggregate `json:"index_iostats_aggregate"`
+	SequenceIOStatsAggregate pginternal.Aggregate `json:"sequence_iostats_aggregate"`
+	TableIOStatsAggregate    pginternal.Aggregate `json:"table_iostats_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
 func (sj *StatTableJoined) ScanDestinations(joins []query.Queryable) []interface{} {
-	dests := []interface{}{
-		&sj.RelID,
-		&sj.SchemaName,
-		&sj.RelName,
-		&sj.NumSequentialScans,
-		&sj.NumSequentialRowsRead,
-		&sj.NumIndexScans,
-		&sj.NumIndexRowsFetched,
-		&sj.NumRowsInserted,
-		&sj.NumRowsUpdated,
-		&sj.NumRowsDeleted,
-		&sj.NumRowsHotUpdated,
-		&sj.NumEstimatedLiveRows,
-		&sj.NumEstimatedDeadRows,
-		&sj.NumRowsModifiedSinceAnalyze,
-		&sj.NumInsertsSinceVacuum,
-		&sj.NumManuallyVacuumed,
-		&sj.LastManuallyVacuumedAt,
-		&sj.NumAutoVacuumed,
-		&sj.LastAutoVacuumedAt,
-		&sj.NumManuallyAnalyzed,
-		&sj.LastManuallyAnalyzedAt,
-		&sj.NumAutoAnalyzed,
-		&sj.LastAutoAnalyzedAt,
-	}
+	dests := sj.StatTable.scanDestinations()
 
 	for _, j := range joins {
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatUserIndexes:
-			joinDest = &sj.Indexes
+			joinDest = j.Destination(&sj.Indexes, &sj.IndexesAggregate)
 		case query.TargetStatSubscription:
-			joinDest = &sj.Subscriptions
+			joinDest = j.Destination(&sj.Subscriptions, &sj.SubscriptionsAggregate)
 		case query.TargetStatIOUserIndexes:
-			joinDest = &sj.IndexIOStats
+			joinDest = j.Destination(&sj.IndexIOStats, &sj.IndexIOStatsAggregate)
 		case query.TargetStatIOUserSequences:
-			joinDest = &sj.SequenceIOStats
+			joinDest = j.Destination(&sj.SequenceIOStats, &sj.SequenceIOStatsAggregate)
 		case query.TargetStatIOUserTables:
-			joinDest = &sj.TableIOStats
+			joinDest = j.Destination(&sj.TableIOStats, &sj.TableIOStatsAggregate)
+		case query.TargetTotalRelationSize:
+			joinDest = &sj.TotalRelationSize
+		case query.TargetRelationSize:
+			joinDest = &sj.RelationSize
+		case query.TargetIndexesSize:
+			joinDest = &sj.IndexesSize
 		}
 		dests = append(dests, joinDest)
 	}
@@ -0,0 +1,65 @@
+package postgres13
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatXactUserFunction represents a row in pg_stat_xact_user_functions, counting
+// the calls of the current transaction only.
+type StatXactUserFunction struct {
+	FuncID     pginternal.OID    `json:"funcid,omitempty"`
+	SchemaName null.String       `json:"schemaname,omitempty"`
+	FuncName   null.String       `json:"funcname,omitempty"`
+	Calls      pginternal.BigInt `json:"calls,omitempty"`
+	TotalTime  null.Float        `json:"total_time,omitempty"`
+	SelfTime   null.Float        `json:"self_time,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatXactUserFunction) Selects() []string {
+	return []string{
+		"funcid",
+		"schemaname",
+		"funcname",
+		"calls",
+		"total_time",
+		"self_time",
+	}
+}
+
+// StatXactUserFunctionJoined is the extended struct of StatXactUserFunction with all the possible joinable fields.
+type StatXactUserFunctionJoined struct {
+	StatXactUserFunction
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatXactUserFunctionJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.FuncID,
+		&sj.SchemaName,
+		&sj.FuncName,
+		&sj.Calls,
+		&sj.TotalTime,
+		&sj.SelfTime,
+	}
+
+	return dests
+}
+
+// StatXactUserFunctions is an alias for a slice of StatXactUserFunctionJoined.
+type StatXactUserFunctions []StatXactUserFunctionJoined
+
+// Scan reads the DB value into StatXactUserFunctions.
+func (ss *StatXactUserFunctions) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatXactUserFunctions to a DB value.
+func (ss *StatXactUserFunctions) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
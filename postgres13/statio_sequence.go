@@ -21,11 +21,11 @@ type StatIOSequence struct {
 // Selects returns the column names for select query.
 func (s *StatIOSequence) Selects() []string {
 	return []string{
-		"pg_statio_user_sequences.relid",
-		"pg_statio_user_sequences.schemaname",
-		"pg_statio_user_sequences.relname",
-		"pg_statio_user_sequences.blks_read",
-		"pg_statio_user_sequences.blks_hit",
+		"relid",
+		"schemaname",
+		"relname",
+		"blks_read",
+		"blks_hit",
 	}
 }
 
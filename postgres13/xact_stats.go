@@ -0,0 +1,38 @@
+package postgres13
+
+import (
+	"time"
+
+	"github.com/sanggonlee/pogo/internal/query"
+)
+
+// XactStats holds the statistics accumulated by a transaction so far, read
+// from the pg_stat_xact_* views within it.
+type XactStats struct {
+	// StartedAt is when the transaction started.
+	StartedAt time.Time `json:"started_at"`
+
+	Tables    StatXactTables        `json:"tables,omitempty"`
+	Functions StatXactUserFunctions `json:"functions,omitempty"`
+}
+
+// ScanDestinations returns the destinations for scanning the statistics row in struct fields.
+// The destination is nil for the views other than the pg_stat_xact_* ones.
+func (s *XactStats) ScanDestinations(views []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&s.StartedAt,
+	}
+
+	for _, v := range views {
+		var dest interface{}
+		switch v.Target {
+		case query.TargetStatXactUserTables:
+			dest = &s.Tables
+		case query.TargetStatXactUserFunctions:
+			dest = &s.Functions
+		}
+		dests = append(dests, dest)
+	}
+
+	return dests
+}
@@ -0,0 +1,80 @@
+package postgres9
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// PreparedXact represents a row in pg_prepared_xacts view.
+// The locks of a prepared transaction are held by the virtual transaction
+// -1/{transaction}, without a pid.
+type PreparedXact struct {
+	Transaction null.String `json:"transaction,omitempty"`
+	GID         null.String `json:"gid,omitempty"`
+	Prepared    null.Time   `json:"prepared,omitempty"`
+	Owner       null.String `json:"owner,omitempty"`
+	Database    null.String `json:"database,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *PreparedXact) Selects() []string {
+	return []string{
+		"transaction",
+		"gid",
+		"prepared",
+		"owner",
+		"database",
+	}
+}
+
+// PreparedXactJoined is the extended struct of PreparedXact with all the possible joinable fields.
+type PreparedXactJoined struct {
+	PreparedXact
+
+	Locks   Locks `json:"locks"`
+	TxLocks Locks `json:"tx_locks"`
+
+	LocksAggregate   pginternal.Aggregate `json:"locks_aggregate"`
+	TxLocksAggregate pginternal.Aggregate `json:"tx_locks_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *PreparedXactJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.Transaction,
+		&sj.GID,
+		&sj.Prepared,
+		&sj.Owner,
+		&sj.Database,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetLocks:
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
+		case query.TargetLocksOnTxID:
+			joinDest = j.Destination(&sj.TxLocks, &sj.TxLocksAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// PreparedXacts is an alias for a slice of PreparedXactJoined.
+type PreparedXacts []PreparedXactJoined
+
+// Scan reads the DB value into PreparedXacts.
+func (ss *PreparedXacts) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts PreparedXacts to a DB value.
+func (ss *PreparedXacts) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
@@ -0,0 +1,123 @@
+package postgres9
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// ReplicationSlot represents a row in pg_replication_slots view.
+// RetainedBytes is computed from restart_lsn, as the bytes of WAL the slot keeps
+// from being removed.
+type ReplicationSlot struct {
+	SlotName          null.String       `json:"slot_name,omitempty"`
+	Plugin            null.String       `json:"plugin,omitempty"`
+	SlotType          null.String       `json:"slot_type,omitempty"`
+	DatOID            pginternal.OID    `json:"datoid,omitempty"`
+	Database          null.String       `json:"database,omitempty"`
+	Active            null.Bool         `json:"active,omitempty"`
+	ActivePID         null.Int          `json:"active_pid,omitempty"`
+	XMin              null.String       `json:"xmin,omitempty"`
+	CatalogXMin       null.String       `json:"catalog_xmin,omitempty"`
+	RestartLSN        pginternal.LSN    `json:"restart_lsn,omitempty"`
+	ConfirmedFlushLSN pginternal.LSN    `json:"confirmed_flush_lsn,omitempty"`
+	RetainedBytes     pginternal.BigInt `json:"retained_bytes,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *ReplicationSlot) Selects() []string {
+	return []string{
+		"slot_name",
+		"plugin",
+		"slot_type",
+		"datoid",
+		"database",
+		"active",
+		"active_pid",
+		"xmin",
+		"catalog_xmin",
+		"restart_lsn",
+		"confirmed_flush_lsn",
+		"retained_bytes",
+	}
+}
+
+// Expressions returns the expressions of the computed columns.
+// On a standby, the WAL retained is counted up to the last WAL replayed.
+func (s *ReplicationSlot) Expressions() map[string]string {
+	return map[string]string{
+		"retained_bytes": `CASE WHEN pg_is_in_recovery()
+			THEN pg_xlog_location_diff(pg_last_xlog_replay_location(), pg_replication_slots.restart_lsn)
+			ELSE pg_xlog_location_diff(pg_current_xlog_location(), pg_replication_slots.restart_lsn) END`,
+	}
+}
+
+// HoldsBackXMin reports whether the slot keeps vacuum from removing the rows
+// deleted after its xmin, which is set for physical slots with hot_standby_feedback.
+func (s *ReplicationSlot) HoldsBackXMin() bool {
+	return s.XMin.Valid
+}
+
+// HoldsBackCatalogXMin reports whether the slot keeps vacuum from removing the
+// system catalog rows deleted after its catalog_xmin, which is set for logical slots.
+func (s *ReplicationSlot) HoldsBackCatalogXMin() bool {
+	return s.CatalogXMin.Valid
+}
+
+// ReplicationSlotJoined is the extended struct of ReplicationSlot with all the possible joinable fields.
+type ReplicationSlotJoined struct {
+	ReplicationSlot
+
+	Activities   StatActivities   `json:"activities"`
+	Replications StatReplications `json:"replications"`
+
+	ActivitiesAggregate   pginternal.Aggregate `json:"activities_aggregate"`
+	ReplicationsAggregate pginternal.Aggregate `json:"replications_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *ReplicationSlotJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.SlotName,
+		&sj.Plugin,
+		&sj.SlotType,
+		&sj.DatOID,
+		&sj.Database,
+		&sj.Active,
+		&sj.ActivePID,
+		&sj.XMin,
+		&sj.CatalogXMin,
+		&sj.RestartLSN,
+		&sj.ConfirmedFlushLSN,
+		&sj.RetainedBytes,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatActivity:
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
+		case query.TargetStatReplication:
+			joinDest = j.Destination(&sj.Replications, &sj.ReplicationsAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// ReplicationSlots is an alias for a slice of ReplicationSlotJoined.
+type ReplicationSlots []ReplicationSlotJoined
+
+// Scan reads the DB value into ReplicationSlots.
+func (ss *ReplicationSlots) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts ReplicationSlots to a DB value.
+func (ss *ReplicationSlots) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/lib/pq"
 	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
 	"github.com/sanggonlee/pogo/internal/query"
 	"gopkg.in/guregu/null.v3"
 )
@@ -35,25 +36,25 @@ type StatActivity struct {
 // Selects returns the column names for select query.
 func (s *StatActivity) Selects() []string {
 	return []string{
-		"pg_stat_activity.datid",
-		"pg_stat_activity.datname",
-		"pg_stat_activity.pid",
-		"pg_stat_activity.usesysid",
-		"pg_stat_activity.usename",
-		"pg_stat_activity.application_name",
-		"pg_stat_activity.client_addr",
-		"pg_stat_activity.client_hostname",
-		"pg_stat_activity.client_port",
-		"pg_stat_activity.backend_start",
-		"pg_stat_activity.xact_start",
-		"pg_stat_activity.query_start",
-		"pg_stat_activity.state_change",
-		"pg_stat_activity.wait_event_type",
-		"pg_stat_activity.wait_event",
-		"pg_stat_activity.state",
-		"pg_stat_activity.backend_xid",
-		"pg_stat_activity.backend_xmin",
-		"pg_stat_activity.query",
+		"datid",
+		"datname",
+		"pid",
+		"usesysid",
+		"usename",
+		"application_name",
+		"client_addr",
+		"client_hostname",
+		"client_port",
+		"backend_start",
+		"xact_start",
+		"query_start",
+		"state_change",
+		"wait_event_type",
+		"wait_event",
+		"state",
+		"backend_xid",
+		"backend_xmin",
+		"query",
 	}
 }
 
@@ -68,6 +69,17 @@ type StatActivityJoined struct {
 	Databases         StatDatabases         `json:"databases,omitempty"`
 	DatabaseConflicts StatDatabaseConflicts `json:"database_conflicts,omitempty"`
 	BlockedBy         pq.Int64Array         `json:"blocked_by,omitempty"`
+
+	SafeSnapshotBlockedBy pq.Int64Array `json:"safe_snapshot_blocked_by,omitempty"`
+	BackendXIDAge         null.Int      `json:"backend_xid_age,omitempty"`
+	BackendXMinAge        null.Int      `json:"backend_xmin_age,omitempty"`
+
+	LocksAggregate             pginternal.Aggregate `json:"locks_aggregate,omitempty"`
+	TxLocksAggregate           pginternal.Aggregate `json:"tx_locks_aggregate,omitempty"`
+	SSLUsagesAggregate         pginternal.Aggregate `json:"ssl_usages_aggregate,omitempty"`
+	WalReciversAggregate       pginternal.Aggregate `json:"wal_receivers_aggregate,omitempty"`
+	DatabasesAggregate         pginternal.Aggregate `json:"databases_aggregate,omitempty"`
+	DatabaseConflictsAggregate pginternal.Aggregate `json:"database_conflicts_aggregate,omitempty"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -98,17 +110,25 @@ func (sj *StatActivityJoined) ScanDestinations(joins []query.Queryable) []interf
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetLocksOnTxID:
-			joinDest = &sj.TxLocks
+			joinDest = j.Destination(&sj.TxLocks, &sj.TxLocksAggregate)
 		case query.TargetStatSSL:
-			joinDest = &sj.SSLUsages
+			joinDest = j.Destination(&sj.SSLUsages, &sj.SSLUsagesAggregate)
 		case query.TargetStatWALReceiver:
-			joinDest = &sj.WalRecivers
+			joinDest = j.Destination(&sj.WalRecivers, &sj.WalReciversAggregate)
 		case query.TargetStatDatabase:
-			joinDest = &sj.Databases
+			joinDest = j.Destination(&sj.Databases, &sj.DatabasesAggregate)
+		case query.TargetStatDatabaseConflicts:
+			joinDest = j.Destination(&sj.DatabaseConflicts, &sj.DatabaseConflictsAggregate)
 		case query.TargetBlockingPIDs:
 			joinDest = &sj.BlockedBy
+		case query.TargetSafeSnapshotBlockingPIDs:
+			joinDest = &sj.SafeSnapshotBlockedBy
+		case query.TargetBackendXIDAge:
+			joinDest = &sj.BackendXIDAge
+		case query.TargetBackendXMinAge:
+			joinDest = &sj.BackendXMinAge
 		}
 		dests = append(dests, joinDest)
 	}
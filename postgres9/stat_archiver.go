@@ -23,13 +23,13 @@ type StatArchiver struct {
 // Selects returns the column names for select query.
 func (s *StatArchiver) Selects() []string {
 	return []string{
-		"pg_stat_archiver.archived_count",
-		"pg_stat_archiver.last_archived_wal",
-		"pg_stat_archiver.last_archived_time",
-		"pg_stat_archiver.failed_count",
-		"pg_stat_archiver.last_failed_wal",
-		"pg_stat_archiver.last_failed_time",
-		"pg_stat_archiver.stats_reset",
+		"archived_count",
+		"last_archived_wal",
+		"last_archived_time",
+		"failed_count",
+		"last_failed_wal",
+		"last_failed_time",
+		"stats_reset",
 	}
 }
 
@@ -35,25 +35,25 @@ type StatDatabase struct {
 // Selects returns the column names for select query.
 func (s *StatDatabase) Selects() []string {
 	return []string{
-		"pg_stat_database.datid",
-		"pg_stat_database.datname",
-		"pg_stat_database.numbackends",
-		"pg_stat_database.xact_commit",
-		"pg_stat_database.xact_rollback",
-		"pg_stat_database.blks_read",
-		"pg_stat_database.blks_hit",
-		"pg_stat_database.tup_returned",
-		"pg_stat_database.tup_fetched",
-		"pg_stat_database.tup_inserted",
-		"pg_stat_database.tup_updated",
-		"pg_stat_database.tup_deleted",
-		"pg_stat_database.conflicts",
-		"pg_stat_database.temp_files",
-		"pg_stat_database.temp_bytes",
-		"pg_stat_database.deadlocks",
-		"pg_stat_database.blk_read_time",
-		"pg_stat_database.blk_write_time",
-		"pg_stat_database.stats_reset",
+		"datid",
+		"datname",
+		"numbackends",
+		"xact_commit",
+		"xact_rollback",
+		"blks_read",
+		"blks_hit",
+		"tup_returned",
+		"tup_fetched",
+		"tup_inserted",
+		"tup_updated",
+		"tup_deleted",
+		"conflicts",
+		"temp_files",
+		"temp_bytes",
+		"deadlocks",
+		"blk_read_time",
+		"blk_write_time",
+		"stats_reset",
 	}
 }
 
@@ -64,6 +64,15 @@ type StatDatabaseJoined struct {
 	Conflicts  StatDatabaseConflicts `json:"conflicts"`
 	Locks      Locks                 `json:"locks"`
 	Activities StatActivities        `json:"activities"`
+	Statements StatStatements        `json:"statements"`
+
+	// DatabaseSize is null for the row of the shared objects, whose datid is 0.
+	DatabaseSize pginternal.BigInt `json:"database_size"`
+
+	ConflictsAggregate  pginternal.Aggregate `json:"conflicts_aggregate"`
+	LocksAggregate      pginternal.Aggregate `json:"locks_aggregate"`
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
+	StatementsAggregate pginternal.Aggregate `json:"statements_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -81,7 +90,7 @@ func (sj *StatDatabaseJoined) ScanDestinations(joins []query.Queryable) []interf
 		&sj.TuplesInserted,
 		&sj.TuplesUpdated,
 		&sj.TuplesDeleted,
-		&sj.Conflicts,
+		&sj.StatDatabase.Conflicts,
 		&sj.TempFiles,
 		&sj.TempBytes,
 		&sj.Deadlocks,
@@ -94,11 +103,15 @@ func (sj *StatDatabaseJoined) ScanDestinations(joins []query.Queryable) []interf
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetStatDatabaseConflicts:
-			joinDest = &sj.Conflicts
+			joinDest = j.Destination(&sj.Conflicts, &sj.ConflictsAggregate)
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatActivity:
-			joinDest = &sj.Activities
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
+		case query.TargetStatStatements:
+			joinDest = j.Destination(&sj.Statements, &sj.StatementsAggregate)
+		case query.TargetDatabaseSize:
+			joinDest = &sj.DatabaseSize
 		}
 		dests = append(dests, joinDest)
 	}
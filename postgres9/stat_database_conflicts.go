@@ -24,13 +24,13 @@ type StatDatabaseConflict struct {
 // Selects returns the column names for select query.
 func (s *StatDatabaseConflict) Selects() []string {
 	return []string{
-		"pg_stat_database_conflicts.datid",
-		"pg_stat_database_conflicts.datname",
-		"pg_stat_database_conflicts.confl_tablespace",
-		"pg_stat_database_conflicts.confl_lock",
-		"pg_stat_database_conflicts.confl_snapshot",
-		"pg_stat_database_conflicts.confl_bufferpin",
-		"pg_stat_database_conflicts.confl_deadlock",
+		"datid",
+		"datname",
+		"confl_tablespace",
+		"confl_lock",
+		"confl_snapshot",
+		"confl_bufferpin",
+		"confl_deadlock",
 	}
 }
 
@@ -24,14 +24,14 @@ type StatIndex struct {
 // Selects returns the column names for select query.
 func (s *StatIndex) Selects() []string {
 	return []string{
-		"pg_stat_user_indexes.relid",
-		"pg_stat_user_indexes.indexrelid",
-		"pg_stat_user_indexes.schemaname",
-		"pg_stat_user_indexes.relname",
-		"pg_stat_user_indexes.indexrelname",
-		"pg_stat_user_indexes.idx_scan",
-		"pg_stat_user_indexes.idx_tup_read",
-		"pg_stat_user_indexes.idx_tup_fetch",
+		"relid",
+		"indexrelid",
+		"schemaname",
+		"relname",
+		"indexrelname",
+		"idx_scan",
+		"idx_tup_read",
+		"idx_tup_fetch",
 	}
 }
 
@@ -43,6 +43,14 @@ type StatIndexJoined struct {
 	TablesIO  StatIOTables  `json:"tables_io"`
 	Locks     Locks         `json:"locks"`
 	IndexesIO StatIOIndexes `json:"indexes_io"`
+
+	TotalRelationSize pginternal.BigInt `json:"total_relation_size"`
+	RelationSize      pginternal.BigInt `json:"relation_size"`
+
+	TablesAggregate    pginternal.Aggregate `json:"tables_aggregate"`
+	TablesIOAggregate  pginternal.Aggregate `json:"tables_io_aggregate"`
+	LocksAggregate     pginternal.Aggregate `json:"locks_aggregate"`
+	IndexesIOAggregate pginternal.Aggregate `json:"indexes_io_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -62,13 +70,17 @@ func (sj *StatIndexJoined) ScanDestinations(joins []query.Queryable) []interface
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetStatUserTables:
-			joinDest = &sj.Tables
+			joinDest = j.Destination(&sj.Tables, &sj.TablesAggregate)
 		case query.TargetStatIOUserTables:
-			joinDest = &sj.TablesIO
+			joinDest = j.Destination(&sj.TablesIO, &sj.TablesIOAggregate)
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatIOUserIndexes:
-			joinDest = &sj.IndexesIO
+			joinDest = j.Destination(&sj.IndexesIO, &sj.IndexesIOAggregate)
+		case query.TargetTotalRelationSize:
+			joinDest = &sj.TotalRelationSize
+		case query.TargetRelationSize:
+			joinDest = &sj.RelationSize
 		}
 		dests = append(dests, joinDest)
 	}
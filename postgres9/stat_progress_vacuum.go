@@ -0,0 +1,124 @@
+package postgres9
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatProgressVacuum represents a row in pg_stat_progress_vacuum view.
+type StatProgressVacuum struct {
+	PID                null.Int          `json:"pid,omitempty"`
+	DatID              pginternal.OID    `json:"datid,omitempty"`
+	DatName            null.String       `json:"datname,omitempty"`
+	RelID              pginternal.OID    `json:"relid,omitempty"`
+	Phase              null.String       `json:"phase,omitempty"`
+	HeapBlocksTotal    pginternal.BigInt `json:"heap_blks_total,omitempty"`
+	HeapBlocksScanned  pginternal.BigInt `json:"heap_blks_scanned,omitempty"`
+	HeapBlocksVacuumed pginternal.BigInt `json:"heap_blks_vacuumed,omitempty"`
+	IndexVacuumCount   pginternal.BigInt `json:"index_vacuum_count,omitempty"`
+	MaxDeadTuples      pginternal.BigInt `json:"max_dead_tuples,omitempty"`
+	NumDeadTuples      pginternal.BigInt `json:"num_dead_tuples,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatProgressVacuum) Selects() []string {
+	return []string{
+		"pid",
+		"datid",
+		"datname",
+		"relid",
+		"phase",
+		"heap_blks_total",
+		"heap_blks_scanned",
+		"heap_blks_vacuumed",
+		"index_vacuum_count",
+		"max_dead_tuples",
+		"num_dead_tuples",
+	}
+}
+
+// VacuumPhase is a phase of a vacuum, as reported in pg_stat_progress_vacuum.
+type VacuumPhase string
+
+// Vacuum phases:
+const (
+	VacuumPhaseInitializing           VacuumPhase = "initializing"
+	VacuumPhaseScanningHeap           VacuumPhase = "scanning heap"
+	VacuumPhaseVacuumingIndexes       VacuumPhase = "vacuuming indexes"
+	VacuumPhaseVacuumingHeap          VacuumPhase = "vacuuming heap"
+	VacuumPhaseCleaningUpIndexes      VacuumPhase = "cleaning up indexes"
+	VacuumPhaseTruncatingHeap         VacuumPhase = "truncating heap"
+	VacuumPhasePerformingFinalCleanup VacuumPhase = "performing final cleanup"
+)
+
+// CurrentPhase returns the phase the vacuum is in.
+func (s *StatProgressVacuum) CurrentPhase() VacuumPhase {
+	return VacuumPhase(s.Phase.String)
+}
+
+// PercentComplete returns the percentage of the heap blocks scanned so far,
+// or vacuumed while in the vacuuming heap phase.
+func (s *StatProgressVacuum) PercentComplete() null.Float {
+	if s.CurrentPhase() == VacuumPhaseVacuumingHeap {
+		return pginternal.Percent(s.HeapBlocksVacuumed, s.HeapBlocksTotal)
+	}
+	return pginternal.Percent(s.HeapBlocksScanned, s.HeapBlocksTotal)
+}
+
+// StatProgressVacuumJoined is the extended struct of StatProgressVacuum with all the possible joinable fields.
+type StatProgressVacuumJoined struct {
+	StatProgressVacuum
+
+	Activities StatActivities `json:"activities"`
+	Tables     StatTables     `json:"tables"`
+
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
+	TablesAggregate     pginternal.Aggregate `json:"tables_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatProgressVacuumJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.PID,
+		&sj.DatID,
+		&sj.DatName,
+		&sj.RelID,
+		&sj.Phase,
+		&sj.HeapBlocksTotal,
+		&sj.HeapBlocksScanned,
+		&sj.HeapBlocksVacuumed,
+		&sj.IndexVacuumCount,
+		&sj.MaxDeadTuples,
+		&sj.NumDeadTuples,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatActivity:
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
+		case query.TargetStatUserTables:
+			joinDest = j.Destination(&sj.Tables, &sj.TablesAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// StatProgressVacuums is an alias for a slice of StatProgressVacuumJoined.
+type StatProgressVacuums []StatProgressVacuumJoined
+
+// Scan reads the DB value into StatProgressVacuums.
+func (ss *StatProgressVacuums) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatProgressVacuums to a DB value.
+func (ss *StatProgressVacuums) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
@@ -32,22 +32,22 @@ type StatReplication struct {
 // Selects returns the column names for select query.
 func (s *StatReplication) Selects() []string {
 	return []string{
-		"pg_stat_replication.pid",
-		"pg_stat_replication.usesysid",
-		"pg_stat_replication.usename",
-		"pg_stat_replication.application_name",
-		"pg_stat_replication.client_addr",
-		"pg_stat_replication.client_hostname",
-		"pg_stat_replication.client_port",
-		"pg_stat_replication.backend_start",
-		"pg_stat_replication.backend_xmin",
-		"pg_stat_replication.state",
-		"pg_stat_replication.sent_location",
-		"pg_stat_replication.write_location",
-		"pg_stat_replication.flush_location",
-		"pg_stat_replication.replay_location",
-		"pg_stat_replication.sync_priority",
-		"pg_stat_replication.sync_state",
+		"pid",
+		"usesysid",
+		"usename",
+		"application_name",
+		"client_addr",
+		"client_hostname",
+		"client_port",
+		"backend_start",
+		"backend_xmin",
+		"state",
+		"sent_location",
+		"write_location",
+		"flush_location",
+		"replay_location",
+		"sync_priority",
+		"sync_state",
 	}
 }
 
@@ -58,6 +58,12 @@ type StatReplicationJoined struct {
 	Locks       Locks            `json:"locks,omitempty"`
 	SSLUsages   StatSSLs         `json:"ssl_usages,omitempty"`
 	WalRecivers StatWALReceivers `json:"wal_receivers,omitempty"`
+
+	ReplayLagBytes pginternal.BigInt `json:"replay_lag_bytes,omitempty"`
+
+	LocksAggregate       pginternal.Aggregate `json:"locks_aggregate,omitempty"`
+	SSLUsagesAggregate   pginternal.Aggregate `json:"ssl_usages_aggregate,omitempty"`
+	WalReciversAggregate pginternal.Aggregate `json:"wal_receivers_aggregate,omitempty"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -85,11 +91,13 @@ func (sj *StatReplicationJoined) ScanDestinations(joins []query.Queryable) []int
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatSSL:
-			joinDest = &sj.SSLUsages
+			joinDest = j.Destination(&sj.SSLUsages, &sj.SSLUsagesAggregate)
 		case query.TargetStatWALReceiver:
-			joinDest = &sj.WalRecivers
+			joinDest = j.Destination(&sj.WalRecivers, &sj.WalReciversAggregate)
+		case query.TargetXLogLocationDiff:
+			joinDest = &sj.ReplayLagBytes
 		}
 		dests = append(dests, joinDest)
 	}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 
 	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
 	"github.com/sanggonlee/pogo/internal/query"
 	"gopkg.in/guregu/null.v3"
 )
@@ -22,13 +23,13 @@ type StatSSL struct {
 // Selects returns the column names for select query.
 func (s *StatSSL) Selects() []string {
 	return []string{
-		"pg_stat_ssl.pid",
-		"pg_stat_ssl.ssl",
-		"pg_stat_ssl.version",
-		"pg_stat_ssl.cipher",
-		"pg_stat_ssl.bits",
-		"pg_stat_ssl.compression",
-		"pg_stat_ssl.clientdn",
+		"pid",
+		"ssl",
+		"version",
+		"cipher",
+		"bits",
+		"compression",
+		"clientdn",
 	}
 }
 
@@ -38,6 +39,9 @@ type StatSSLJoined struct {
 
 	Locks      Locks          `json:"locks"`
 	Activities StatActivities `json:"activities"`
+
+	LocksAggregate      pginternal.Aggregate `json:"locks_aggregate"`
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -56,9 +60,9 @@ func (sj *StatSSLJoined) ScanDestinations(joins []query.Queryable) []interface{}
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatActivity:
-			joinDest = &sj.Activities
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
 		}
 		dests = append(dests, joinDest)
 	}
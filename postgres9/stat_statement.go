@@ -0,0 +1,129 @@
+package postgres9
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatStatement represents a row in pg_stat_statements view of the
+// pg_stat_statements extension, as of its version 1.4 shipped with Postgres 9.6.
+type StatStatement struct {
+	UserID              pginternal.OID    `json:"userid,omitempty"`
+	DBID                pginternal.OID    `json:"dbid,omitempty"`
+	QueryID             pginternal.BigInt `json:"queryid,omitempty"`
+	Query               null.String       `json:"query,omitempty"`
+	Calls               pginternal.BigInt `json:"calls,omitempty"`
+	TotalTime           null.Float        `json:"total_time,omitempty"`
+	MinTime             null.Float        `json:"min_time,omitempty"`
+	MaxTime             null.Float        `json:"max_time,omitempty"`
+	MeanTime            null.Float        `json:"mean_time,omitempty"`
+	StddevTime          null.Float        `json:"stddev_time,omitempty"`
+	Rows                pginternal.BigInt `json:"rows,omitempty"`
+	SharedBlocksHit     pginternal.BigInt `json:"shared_blks_hit,omitempty"`
+	SharedBlocksRead    pginternal.BigInt `json:"shared_blks_read,omitempty"`
+	SharedBlocksDirtied pginternal.BigInt `json:"shared_blks_dirtied,omitempty"`
+	SharedBlocksWritten pginternal.BigInt `json:"shared_blks_written,omitempty"`
+	LocalBlocksHit      pginternal.BigInt `json:"local_blks_hit,omitempty"`
+	LocalBlocksRead     pginternal.BigInt `json:"local_blks_read,omitempty"`
+	LocalBlocksDirtied  pginternal.BigInt `json:"local_blks_dirtied,omitempty"`
+	LocalBlocksWritten  pginternal.BigInt `json:"local_blks_written,omitempty"`
+	TempBlocksRead      pginternal.BigInt `json:"temp_blks_read,omitempty"`
+	TempBlocksWritten   pginternal.BigInt `json:"temp_blks_written,omitempty"`
+	BlockReadTime       null.Float        `json:"blk_read_time,omitempty"`
+	BlockWriteTime      null.Float        `json:"blk_write_time,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatStatement) Selects() []string {
+	return []string{
+		"userid",
+		"dbid",
+		"queryid",
+		"query",
+		"calls",
+		"total_time",
+		"min_time",
+		"max_time",
+		"mean_time",
+		"stddev_time",
+		"rows",
+		"shared_blks_hit",
+		"shared_blks_read",
+		"shared_blks_dirtied",
+		"shared_blks_written",
+		"local_blks_hit",
+		"local_blks_read",
+		"local_blks_dirtied",
+		"local_blks_written",
+		"temp_blks_read",
+		"temp_blks_written",
+		"blk_read_time",
+		"blk_write_time",
+	}
+}
+
+// StatStatementJoined is the extended struct of StatStatement with all the possible joinable fields.
+type StatStatementJoined struct {
+	StatStatement
+
+	Databases StatDatabases `json:"databases"`
+
+	DatabasesAggregate pginternal.Aggregate `json:"databases_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatStatementJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.UserID,
+		&sj.DBID,
+		&sj.QueryID,
+		&sj.Query,
+		&sj.Calls,
+		&sj.TotalTime,
+		&sj.MinTime,
+		&sj.MaxTime,
+		&sj.MeanTime,
+		&sj.StddevTime,
+		&sj.Rows,
+		&sj.SharedBlocksHit,
+		&sj.SharedBlocksRead,
+		&sj.SharedBlocksDirtied,
+		&sj.SharedBlocksWritten,
+		&sj.LocalBlocksHit,
+		&sj.LocalBlocksRead,
+		&sj.LocalBlocksDirtied,
+		&sj.LocalBlocksWritten,
+		&sj.TempBlocksRead,
+		&sj.TempBlocksWritten,
+		&sj.BlockReadTime,
+		&sj.BlockWriteTime,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatDatabase:
+			joinDest = j.Destination(&sj.Databases, &sj.DatabasesAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// StatStatements is an alias for a slice of StatStatementJoined.
+type StatStatements []StatStatementJoined
+
+// Scan reads the DB value into StatStatements.
+func (ss *StatStatements) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatStatements to a DB value.
+func (ss *StatStatements) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
@@ -22,12 +22,12 @@ type StatUserFunction struct {
 // Selects returns the column names for select query.
 func (s *StatUserFunction) Selects() []string {
 	return []string{
-		"pg_stat_user_functions.funcid",
-		"pg_stat_user_functions.schemaname",
-		"pg_stat_user_functions.funcname",
-		"pg_stat_user_functions.calls",
-		"pg_stat_user_functions.total_time",
-		"pg_stat_user_functions.self_time",
+		"funcid",
+		"schemaname",
+		"funcname",
+		"calls",
+		"total_time",
+		"self_time",
 	}
 }
 
@@ -28,18 +28,18 @@ type StatWALReceiver struct {
 // Selects returns the column names for select query.
 func (s *StatWALReceiver) Selects() []string {
 	return []string{
-		"pg_stat_wal_receiver.pid",
-		"pg_stat_wal_receiver.status",
-		"pg_stat_wal_receiver.receive_start_lsn",
-		"pg_stat_wal_receiver.receive_start_tli",
-		"pg_stat_wal_receiver.received_lsn",
-		"pg_stat_wal_receiver.received_tli",
-		"pg_stat_wal_receiver.last_msg_send_time",
-		"pg_stat_wal_receiver.last_msg_receipt_time",
-		"pg_stat_wal_receiver.latest_end_lsn",
-		"pg_stat_wal_receiver.latest_end_time",
-		"pg_stat_wal_receiver.slot_name",
-		"pg_stat_wal_receiver.conninfo",
+		"pid",
+		"status",
+		"receive_start_lsn",
+		"receive_start_tli",
+		"received_lsn",
+		"received_tli",
+		"last_msg_send_time",
+		"last_msg_receipt_time",
+		"latest_end_lsn",
+		"latest_end_time",
+		"slot_name",
+		"conninfo",
 	}
 }
 
@@ -49,6 +49,9 @@ type StatWALReceiverJoined struct {
 
 	Locks      Locks          `json:"locks"`
 	Activities StatActivities `json:"activities"`
+
+	LocksAggregate      pginternal.Aggregate `json:"locks_aggregate"`
+	ActivitiesAggregate pginternal.Aggregate `json:"activities_aggregate"`
 }
 
 // ScanDestinations returns the destinations for scanning DB rows in struct fields.
@@ -72,9 +75,9 @@ func (sj *StatWALReceiverJoined) ScanDestinations(joins []query.Queryable) []int
 		var joinDest interface{}
 		switch j.Target {
 		case query.TargetLocks:
-			joinDest = &sj.Locks
+			joinDest = j.Destination(&sj.Locks, &sj.LocksAggregate)
 		case query.TargetStatActivity:
-			joinDest = &sj.Activities
+			joinDest = j.Destination(&sj.Activities, &sj.ActivitiesAggregate)
 		}
 		dests = append(dests, joinDest)
 	}
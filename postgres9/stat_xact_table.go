@@ -0,0 +1,93 @@
+package postgres9
+
+import (
+	"database/sql/driver"
+
+	"github.com/sanggonlee/pogo/internal/convert"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"gopkg.in/guregu/null.v3"
+)
+
+// StatXactTable represents a row in pg_stat_xact_{all,sys,user}_tables, counting
+// the activity of the current transaction only.
+type StatXactTable struct {
+	RelID                 null.Int    `json:"relid,omitempty"`
+	SchemaName            null.String `json:"schemaname,omitempty"`
+	RelName               null.String `json:"relname,omitempty"`
+	NumSequentialScans    null.Int    `json:"seq_scan,omitempty"`
+	NumSequentialRowsRead null.Int    `json:"seq_tup_read,omitempty"`
+	NumIndexScans         null.Int    `json:"idx_scan,omitempty"`
+	NumIndexRowsFetched   null.Int    `json:"idx_tup_fetch,omitempty"`
+	NumRowsInserted       null.Int    `json:"n_tup_ins,omitempty"`
+	NumRowsUpdated        null.Int    `json:"n_tup_upd,omitempty"`
+	NumRowsDeleted        null.Int    `json:"n_tup_del,omitempty"`
+	NumRowsHotUpdated     null.Int    `json:"n_tup_hot_upd,omitempty"`
+}
+
+// Selects returns the column names for select query.
+func (s *StatXactTable) Selects() []string {
+	return []string{
+		"relid",
+		"schemaname",
+		"relname",
+		"seq_scan",
+		"seq_tup_read",
+		"idx_scan",
+		"idx_tup_fetch",
+		"n_tup_ins",
+		"n_tup_upd",
+		"n_tup_del",
+		"n_tup_hot_upd",
+	}
+}
+
+// StatXactTableJoined is the extended struct of StatXactTable with all the possible joinable fields.
+type StatXactTableJoined struct {
+	StatXactTable
+
+	Tables StatTables `json:"tables"`
+
+	TablesAggregate pginternal.Aggregate `json:"tables_aggregate"`
+}
+
+// ScanDestinations returns the destinations for scanning DB rows in struct fields.
+func (sj *StatXactTableJoined) ScanDestinations(joins []query.Queryable) []interface{} {
+	dests := []interface{}{
+		&sj.RelID,
+		&sj.SchemaName,
+		&sj.RelName,
+		&sj.NumSequentialScans,
+		&sj.NumSequentialRowsRead,
+		&sj.NumIndexScans,
+		&sj.NumIndexRowsFetched,
+		&sj.NumRowsInserted,
+		&sj.NumRowsUpdated,
+		&sj.NumRowsDeleted,
+		&sj.NumRowsHotUpdated,
+	}
+
+	for _, j := range joins {
+		var joinDest interface{}
+		switch j.Target {
+		case query.TargetStatUserTables:
+			joinDest = j.Destination(&sj.Tables, &sj.TablesAggregate)
+		}
+		dests = append(dests, joinDest)
+	}
+
+	return dests
+}
+
+// StatXactTables is an alias for a slice of StatXactTableJoined.
+type StatXactTables []StatXactTableJoined
+
+// Scan reads the DB value into StatXactTables.
+func (ss *StatXactTables) Scan(value interface{}) error {
+	return convert.JSONScan(ss, value)
+}
+
+// Value converts StatXactTables to a DB value.
+func (ss *StatXactTables) Value() (driver.Value, error) {
+	return convert.JSONValue(ss)
+}
@@ -23,13 +23,13 @@ type StatIOIndex struct {
 // Selects returns the column names for select query.
 func (s *StatIOIndex) Selects() []string {
 	return []string{
-		"pg_statio_user_indexes.relid",
-		"pg_statio_user_indexes.indexrelid",
-		"pg_statio_user_indexes.schemaname",
-		"pg_statio_user_indexes.relname",
-		"pg_statio_user_indexes.indexrelname",
-		"pg_statio_user_indexes.idx_blks_read",
-		"pg_statio_user_indexes.idx_blks_hit",
+		"relid",
+		"indexrelid",
+		"schemaname",
+		"relname",
+		"indexrelname",
+		"idx_blks_read",
+		"idx_blks_hit",
 	}
 }
 
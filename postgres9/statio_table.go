@@ -27,17 +27,17 @@ type StatIOTable struct {
 // Selects returns the column names for select query.
 func (s *StatIOTable) Selects() []string {
 	return []string{
-		"pg_statio_user_tables.relid",
-		"pg_statio_user_tables.schemaname",
-		"pg_statio_user_tables.relname",
-		"pg_statio_user_tables.heap_blks_read",
-		"pg_statio_user_tables.heap_blks_hit",
-		"pg_statio_user_tables.idx_blks_read",
-		"pg_statio_user_tables.idx_blks_hit",
-		"pg_statio_user_tables.toast_blks_read",
-		"pg_statio_user_tables.toast_blks_hit",
-		"pg_statio_user_tables.tidx_blks_read",
-		"pg_statio_user_tables.tidx_blks_hit",
+		"relid",
+		"schemaname",
+		"relname",
+		"heap_blks_read",
+		"heap_blks_hit",
+		"idx_blks_read",
+		"idx_blks_hit",
+		"toast_blks_read",
+		"toast_blks_hit",
+		"tidx_blks_read",
+		"tidx_blks_hit",
 	}
 }
 
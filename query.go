@@ -3,18 +3,25 @@ package pogo
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sanggonlee/pogo/internal/query"
 	"github.com/sanggonlee/pogo/internal/version"
 )
 
+var (
+	errNoQueryor = errors.New("no database/sql queryor to run the query with, use Rows instead")
+)
+
 // Query prepares a query running instance. Note that it does not run a
 // query by itself.
 func Query(queryor Queryor) QueryRunner {
 	safeguardPostgresVersion()
 	return QueryRunner{
-		queryor: queryor,
+		queryor:     queryor,
+		rowsQueryor: sqlQueryor{queryor},
 	}
 }
 
@@ -22,31 +29,215 @@ func Query(queryor Queryor) QueryRunner {
 func QueryContext(ctx context.Context, queryor Queryor) QueryRunner {
 	safeguardPostgresVersion()
 	return QueryRunner{
-		queryor: queryor,
-		ctx:     ctx,
+		queryor:     queryor,
+		rowsQueryor: sqlQueryor{queryor},
+		ctx:         ctx,
+	}
+}
+
+// QueryWith is like Query, except it queries the database through a driver
+// other than database/sql. The rows are read with QueryRunner.Rows rather than
+// QueryRunner.For, and the convenience methods work as they are.
+func QueryWith(queryor RowsQueryor) QueryRunner {
+	safeguardPostgresVersion()
+	return QueryRunner{
+		rowsQueryor: queryor,
+	}
+}
+
+// QueryWithContext is like QueryWith, except it also takes a context.
+func QueryWithContext(ctx context.Context, queryor RowsQueryor) QueryRunner {
+	safeguardPostgresVersion()
+	return QueryRunner{
+		rowsQueryor: queryor,
+		ctx:         ctx,
 	}
 }
 
 // QueryRunner is an encapsulation for running a single query.
 type QueryRunner struct {
-	queryor Queryor
-	ctx     context.Context
+	queryor     Queryor
+	rowsQueryor RowsQueryor
+	ctx         context.Context
+	options     []Option
+	hooks       []Hooks
+}
+
+// Options sets the options applied to the top-level queryable of the
+// convenience methods. For example, the 20 oldest transactions:
+//
+//	pogo.Query(db).
+//		Options(pogo.OrderBy(pogo.Asc("xact_start")), pogo.Limit(20)).
+//		StatActivity13("xact_start IS NOT NULL")
+func (qr QueryRunner) Options(opts ...Option) QueryRunner {
+	_qr := qr
+	_qr.options = append(append([]Option{}, qr.options...), opts...)
+	return _qr
+}
+
+// applyOptions applies the options of the runner to the queryable.
+func (qr QueryRunner) applyOptions(queryable query.Queryable) query.Queryable {
+	for _, opt := range qr.options {
+		queryable = opt(queryable)
+	}
+	return queryable
+}
+
+// backendExcluder is implemented by the queryors asking for the rows of their
+// own backend to be left out, such as Session.
+type backendExcluder interface {
+	excludesOwnBackend() bool
+}
+
+// excludeOwnBackend leaves out the rows of the queryor's own backend from the
+// queryable, if the queryor asks for it.
+func (qr QueryRunner) excludeOwnBackend(queryable query.Queryable) query.Queryable {
+	if qr.excludesOwnBackend() {
+		return queryable.ExcludeOwnBackend()
+	}
+	return queryable
+}
+
+func (qr QueryRunner) excludesOwnBackend() bool {
+	if e, ok := qr.queryor.(backendExcluder); ok && e.excludesOwnBackend() {
+		return true
+	}
+	if e, ok := qr.rowsQueryor.(backendExcluder); ok && e.excludesOwnBackend() {
+		return true
+	}
+	return false
 }
 
 // For is used to run a query on arbitrary relations. It returns sql.Rows, which
-// you can scan to the structure you see fit.
+// you can scan to the structure you see fit. It needs a Queryor, given to Query
+// or QueryContext.
 func (qr QueryRunner) For(queryable query.Queryable) (*sql.Rows, error) {
-	q, err := queryable.ToQuery()
+	if qr.queryor == nil {
+		return nil, errNoQueryor
+	}
+
+	queryable = qr.excludeOwnBackend(queryable)
+	q, args, err := queryable.ToQueryArgs()
 	if err != nil {
 		return nil, errors.Wrap(err, "converting queryable to query string")
 	}
 
+	var ctx context.Context
+	var start time.Time
+	if len(qr.hooks) > 0 {
+		ctx, start = qr.beforeQuery([]query.Queryable{queryable}, q, args)
+	}
+
 	var rows *sql.Rows
-	if qr.ctx == nil {
-		rows, err = qr.queryor.Query(q)
+	if queryCtx := qr.queryContext(ctx); queryCtx == nil {
+		rows, err = qr.queryor.Query(q, args...)
 	} else {
-		rows, err = qr.queryor.QueryContext(qr.ctx, q)
+		rows, err = qr.queryor.QueryContext(queryCtx, q, args...)
+	}
+	err = extensionError([]query.Queryable{queryable}, err)
+	if len(qr.hooks) > 0 {
+		qr.afterQuery(ctx, q, start, -1, err)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "querying rows")
 	}
+
+	return rows, nil
+}
+
+// query runs the query generated from the queryables with args, notifying the
+// hooks.
+func (qr QueryRunner) query(queryables []query.Queryable, q string, args ...interface{}) (Rows, error) {
+	if len(qr.hooks) == 0 {
+		rows, err := qr.rowsQueryor.QueryRows(qr.ctx, q, args...)
+		if err != nil {
+			return nil, extensionError(queryables, err)
+		}
+		return qr.extensionRows(queryables, rows), nil
+	}
+
+	ctx, start := qr.beforeQuery(queryables, q, args)
+	rows, err := qr.rowsQueryor.QueryRows(qr.queryContext(ctx), q, args...)
+	if err != nil {
+		err = extensionError(queryables, err)
+		qr.afterQuery(ctx, q, start, 0, err)
+		return nil, err
+	}
+	rows = qr.extensionRows(queryables, rows)
+
+	return &hookedRows{
+		Rows:   rows,
+		runner: qr,
+		ctx:    ctx,
+		query:  q,
+		start:  start,
+	}, nil
+}
+
+// extensionRows maps the errors of the rows of the queryables on extensions
+// that may not be installed.
+func (qr QueryRunner) extensionRows(queryables []query.Queryable, rows Rows) Rows {
+	if involves(queryables, query.TargetStatStatements) {
+		return extensionRows{Rows: rows, queryables: queryables}
+	}
+	return rows
+}
+
+// queryContext returns the context to run the query with, given the one
+// returned by the hooks if any: none if neither the runner nor the hooks
+// have one.
+func (qr QueryRunner) queryContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return qr.ctx
+	}
+	return ctx
+}
+
+// snapshot reads the views in a single query and scans the resulting row into s.
+func (qr QueryRunner) snapshot(s query.Scannable, views []query.Queryable) error {
+	dests := s.ScanDestinations(views)
+	for i, v := range views {
+		if dests[i+1] == nil {
+			return fmt.Errorf("%s is not supported in snapshots", v.Target)
+		}
+	}
+
+	excluded := make([]query.Queryable, 0, len(views))
+	for _, v := range views {
+		excluded = append(excluded, qr.excludeOwnBackend(v))
+	}
+	q, args, err := query.ToSnapshotQuery(excluded)
+	if err != nil {
+		return errors.Wrap(err, "converting views to snapshot query")
+	}
+
+	row, err := qr.query(excluded, q, args...)
+	if err != nil {
+		return errors.Wrap(err, "querying snapshot")
+	}
+	defer row.Close()
+
+	if !row.Next() {
+		if err := row.Err(); err != nil {
+			return errors.Wrap(err, "reading snapshot row")
+		}
+		return sql.ErrNoRows
+	}
+	if err := row.Scan(dests...); err != nil {
+		return errors.Wrap(err, "scanning snapshot row")
+	}
+	return nil
+}
+
+// Rows is like For, except it returns Rows, which works with any driver.
+func (qr QueryRunner) Rows(queryable query.Queryable) (Rows, error) {
+	queryable = qr.excludeOwnBackend(queryable)
+	q, args, err := queryable.ToQueryArgs()
+	if err != nil {
+		return nil, errors.Wrap(err, "converting queryable to query string")
+	}
+
+	rows, err := qr.query([]query.Queryable{queryable}, q, args...)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying rows")
 	}
@@ -1,6 +1,9 @@
 package pogo
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/sanggonlee/pogo/internal/query"
 	"github.com/sanggonlee/pogo/postgres13"
@@ -9,17 +12,19 @@ import (
 // StatActivity13 is a convenience method for running a query on pg_stat_activity view.
 // It is meant to be used for Postgres v13.
 // If you want to select rows with certain conditions, pass a non-empty where argument,
-// which will be injected as WHERE {where} in the query.
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
 func (qr QueryRunner) StatActivity13(where string, joins ...query.Queryable) ([]postgres13.StatActivityJoined, error) {
 	if v := GetPostgresVersion(); v != Postgres13 {
 		return nil, getVersionMismatchError(Postgres13, v)
 	}
 
-	queryable := StatActivityView.
+	queryable := qr.applyOptions(StatActivityView.
 		Where(where).
-		With(joins...)
+		With(joins...),
+	)
 
-	rows, err := qr.For(queryable)
+	rows, err := qr.Rows(queryable)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying pg_stat_activity")
 	}
@@ -28,7 +33,7 @@ func (qr QueryRunner) StatActivity13(where string, joins ...query.Queryable) ([]
 	ss := make([]postgres13.StatActivityJoined, 0)
 	for rows.Next() {
 		var s postgres13.StatActivityJoined
-		dest := s.ScanDestinations(queryable.Joins)
+		dest := queryable.ScanDestinations(&s)
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.Wrap(err, "scanning pg_stat_activity row")
@@ -43,17 +48,19 @@ func (qr QueryRunner) StatActivity13(where string, joins ...query.Queryable) ([]
 // StatReplication13 is a convenience method for running a query on pg_stat_replication view.
 // It is meant to be used for Postgres v13.
 // If you want to select rows with certain conditions, pass a non-empty where argument,
-// which will be injected as WHERE {where} in the query.
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
 func (qr QueryRunner) StatReplication13(where string, joins ...query.Queryable) ([]postgres13.StatReplicationJoined, error) {
 	if v := GetPostgresVersion(); v != Postgres13 {
 		return nil, getVersionMismatchError(Postgres13, v)
 	}
 
-	queryable := StatReplicationView.
+	queryable := qr.applyOptions(StatReplicationView.
 		Where(where).
-		With(joins...)
+		With(joins...),
+	)
 
-	rows, err := qr.For(queryable)
+	rows, err := qr.Rows(queryable)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying pg_stat_replication")
 	}
@@ -62,7 +69,7 @@ func (qr QueryRunner) StatReplication13(where string, joins ...query.Queryable)
 	ss := make([]postgres13.StatReplicationJoined, 0)
 	for rows.Next() {
 		var s postgres13.StatReplicationJoined
-		dest := s.ScanDestinations(queryable.Joins)
+		dest := queryable.ScanDestinations(&s)
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.Wrap(err, "scanning pg_stat_replication row")
@@ -77,17 +84,19 @@ func (qr QueryRunner) StatReplication13(where string, joins ...query.Queryable)
 // StatTable13 is a convenience method for running a query on pg_stat_user_tables view.
 // It is meant to be used for Postgres v13.
 // If you want to select rows with certain conditions, pass a non-empty where argument,
-// which will be injected as WHERE {where} in the query.
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
 func (qr QueryRunner) StatTable13(where string, joins ...query.Queryable) ([]postgres13.StatTableJoined, error) {
 	if v := GetPostgresVersion(); v != Postgres13 {
 		return nil, getVersionMismatchError(Postgres13, v)
 	}
 
-	queryable := StatUserTablesView.
+	queryable := qr.applyOptions(StatUserTablesView.
 		Where(where).
-		With(joins...)
+		With(joins...),
+	)
 
-	rows, err := qr.For(queryable)
+	rows, err := qr.Rows(queryable)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying pg_stat_user_tables")
 	}
@@ -96,7 +105,7 @@ func (qr QueryRunner) StatTable13(where string, joins ...query.Queryable) ([]pos
 	ss := make([]postgres13.StatTableJoined, 0)
 	for rows.Next() {
 		var s postgres13.StatTableJoined
-		dest := s.ScanDestinations(queryable.Joins)
+		dest := queryable.ScanDestinations(&s)
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.Wrap(err, "scanning pg_stat_user_tables row")
@@ -111,17 +120,19 @@ func (qr QueryRunner) StatTable13(where string, joins ...query.Queryable) ([]pos
 // Locks13 is a convenience method for running a query on pg_locks view.
 // It is meant to be used for Postgres v13.
 // If you want to select rows with certain conditions, pass a non-empty where argument,
-// which will be injected as WHERE {where} in the query.
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
 func (qr QueryRunner) Locks13(where string, joins ...query.Queryable) ([]postgres13.LockJoined, error) {
 	if v := GetPostgresVersion(); v != Postgres13 {
 		return nil, getVersionMismatchError(Postgres13, v)
 	}
 
-	queryable := LocksView.
+	queryable := qr.applyOptions(LocksView.
 		Where(where).
-		With(joins...)
+		With(joins...),
+	)
 
-	rows, err := qr.For(queryable)
+	rows, err := qr.Rows(queryable)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying pg_locks")
 	}
@@ -130,7 +141,7 @@ func (qr QueryRunner) Locks13(where string, joins ...query.Queryable) ([]postgre
 	ls := make([]postgres13.LockJoined, 0)
 	for rows.Next() {
 		var l postgres13.LockJoined
-		dest := l.ScanDestinations(queryable.Joins)
+		dest := queryable.ScanDestinations(&l)
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.Wrap(err, "scanning pg_locks row")
@@ -141,3 +152,103 @@ func (qr QueryRunner) Locks13(where string, joins ...query.Queryable) ([]postgre
 
 	return ls, nil
 }
+
+// StatStatements13 is a convenience method for running a query on pg_stat_statements view.
+// It is meant to be used for Postgres v13.
+// If you want to select rows with certain conditions, pass a non-empty where argument,
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
+// It fails with an error wrapping ErrStatStatementsNotInstalled if the extension
+// isn't created in the database.
+func (qr QueryRunner) StatStatements13(where string, joins ...query.Queryable) ([]postgres13.StatStatementJoined, error) {
+	if v := GetPostgresVersion(); v != Postgres13 {
+		return nil, getVersionMismatchError(Postgres13, v)
+	}
+
+	queryable := qr.applyOptions(StatStatementsView.
+		Where(where).
+		With(joins...),
+	)
+
+	rows, err := qr.Rows(queryable)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying pg_stat_statements")
+	}
+	defer rows.Close()
+
+	ss := make([]postgres13.StatStatementJoined, 0)
+	for rows.Next() {
+		var s postgres13.StatStatementJoined
+		dest := queryable.ScanDestinations(&s)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scanning pg_stat_statements row")
+		}
+
+		ss = append(ss, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading pg_stat_statements rows")
+	}
+
+	return ss, nil
+}
+
+// OldPreparedXacts13 returns the transactions prepared more than olderThan ago,
+// oldest first, along with the locks they hold in Locks and the locks waiting
+// for them in TxLocks. Forgotten prepared transactions keep holding their locks
+// and the xmin horizon until they're committed or rolled back with COMMIT
+// PREPARED or ROLLBACK PREPARED.
+// It is meant to be used for Postgres v13.
+func (qr QueryRunner) OldPreparedXacts13(olderThan time.Duration) ([]postgres13.PreparedXactJoined, error) {
+	if v := GetPostgresVersion(); v != Postgres13 {
+		return nil, getVersionMismatchError(Postgres13, v)
+	}
+
+	queryable := qr.applyOptions(PreparedXactsView.
+		Where(fmt.Sprintf("prepared < now() - interval '%s'", milliseconds(olderThan))).
+		OrderBy(Asc("prepared")).
+		With(
+			LocksView,
+			LocksOnTxIDView.Where("NOT pg_locks.granted"),
+		),
+	)
+
+	rows, err := qr.Rows(queryable)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying pg_prepared_xacts")
+	}
+	defer rows.Close()
+
+	ps := make([]postgres13.PreparedXactJoined, 0)
+	for rows.Next() {
+		var p postgres13.PreparedXactJoined
+		dest := queryable.ScanDestinations(&p)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scanning pg_prepared_xacts row")
+		}
+
+		ps = append(ps, p)
+	}
+
+	return ps, nil
+}
+
+// Snapshot13 reads the given views in a single query, so that their rows are
+// consistent with each other: the statistics views are all read from the same
+// snapshot of the statistics, taken at the first access in the transaction.
+// Each view can be given once, with its own Where, joins and options. The views
+// not given are left nil in the returned snapshot.
+// It is meant to be used for Postgres v13.
+func (qr QueryRunner) Snapshot13(views ...query.Queryable) (postgres13.Snapshot, error) {
+	if v := GetPostgresVersion(); v != Postgres13 {
+		return postgres13.Snapshot{}, getVersionMismatchError(Postgres13, v)
+	}
+
+	var s postgres13.Snapshot
+	if err := qr.snapshot(&s, views); err != nil {
+		return postgres13.Snapshot{}, err
+	}
+	return s, nil
+}
@@ -1,6 +1,9 @@
 package pogo
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/sanggonlee/pogo/internal/query"
 	"github.com/sanggonlee/pogo/postgres9"
@@ -9,17 +12,19 @@ import (
 // StatActivity9 is a convenience method for running a query on pg_stat_activity view.
 // It is meant to be used for Postgres v9.6.
 // If you want to select rows with certain conditions, pass a non-empty where argument,
-// which will be injected as WHERE {where} in the query.
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
 func (qr QueryRunner) StatActivity9(where string, joins ...query.Queryable) ([]postgres9.StatActivityJoined, error) {
 	if v := GetPostgresVersion(); v != Postgres9 {
 		return nil, getVersionMismatchError(Postgres9, v)
 	}
 
-	queryable := StatActivityView.
+	queryable := qr.applyOptions(StatActivityView.
 		Where(where).
-		With(joins...)
+		With(joins...),
+	)
 
-	rows, err := qr.For(queryable)
+	rows, err := qr.Rows(queryable)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying pg_stat_activity")
 	}
@@ -28,7 +33,7 @@ func (qr QueryRunner) StatActivity9(where string, joins ...query.Queryable) ([]p
 	ss := make([]postgres9.StatActivityJoined, 0)
 	for rows.Next() {
 		var s postgres9.StatActivityJoined
-		dest := s.ScanDestinations(queryable.Joins)
+		dest := queryable.ScanDestinations(&s)
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.Wrap(err, "scanning pg_stat_activity row")
@@ -43,17 +48,19 @@ func (qr QueryRunner) StatActivity9(where string, joins ...query.Queryable) ([]p
 // StatReplication9 is a convenience method for running a query on pg_stat_replication view.
 // It is meant to be used for Postgres v9.6.
 // If you want to select rows with certain conditions, pass a non-empty where argument,
-// which will be injected as WHERE {where} in the query.
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
 func (qr QueryRunner) StatReplication9(where string, joins ...query.Queryable) ([]postgres9.StatReplicationJoined, error) {
 	if v := GetPostgresVersion(); v != Postgres9 {
 		return nil, getVersionMismatchError(Postgres9, v)
 	}
 
-	queryable := StatReplicationView.
+	queryable := qr.applyOptions(StatReplicationView.
 		Where(where).
-		With(joins...)
+		With(joins...),
+	)
 
-	rows, err := qr.For(queryable)
+	rows, err := qr.Rows(queryable)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying pg_stat_replication")
 	}
@@ -62,7 +69,7 @@ func (qr QueryRunner) StatReplication9(where string, joins ...query.Queryable) (
 	ss := make([]postgres9.StatReplicationJoined, 0)
 	for rows.Next() {
 		var s postgres9.StatReplicationJoined
-		dest := s.ScanDestinations(queryable.Joins)
+		dest := queryable.ScanDestinations(&s)
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.Wrap(err, "scanning pg_stat_replication row")
@@ -77,17 +84,19 @@ func (qr QueryRunner) StatReplication9(where string, joins ...query.Queryable) (
 // StatTable9 is a convenience method for running a query on pg_stat_user_tables view.
 // It is meant to be used for Postgres v9.6.
 // If you want to select rows with certain conditions, pass a non-empty where argument,
-// which will be injected as WHERE {where} in the query.
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
 func (qr QueryRunner) StatTable9(where string, joins ...query.Queryable) ([]postgres9.StatTableJoined, error) {
 	if v := GetPostgresVersion(); v != Postgres9 {
 		return nil, getVersionMismatchError(Postgres9, v)
 	}
 
-	queryable := StatUserTablesView.
+	queryable := qr.applyOptions(StatUserTablesView.
 		Where(where).
-		With(joins...)
+		With(joins...),
+	)
 
-	rows, err := qr.For(queryable)
+	rows, err := qr.Rows(queryable)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying pg_stat_user_tables")
 	}
@@ -96,7 +105,7 @@ func (qr QueryRunner) StatTable9(where string, joins ...query.Queryable) ([]post
 	ss := make([]postgres9.StatTableJoined, 0)
 	for rows.Next() {
 		var s postgres9.StatTableJoined
-		dest := s.ScanDestinations(queryable.Joins)
+		dest := queryable.ScanDestinations(&s)
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.Wrap(err, "scanning pg_stat_user_tables row")
@@ -111,17 +120,19 @@ func (qr QueryRunner) StatTable9(where string, joins ...query.Queryable) ([]post
 // Locks9 is a convenience method for running a query on pg_locks view.
 // It is meant to be used for Postgres v9.6.
 // If you want to select rows with certain conditions, pass a non-empty where argument,
-// which will be injected as WHERE {where} in the query.
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
 func (qr QueryRunner) Locks9(where string, joins ...query.Queryable) ([]postgres9.LockJoined, error) {
 	if v := GetPostgresVersion(); v != Postgres9 {
 		return nil, getVersionMismatchError(Postgres9, v)
 	}
 
-	queryable := LocksView.
+	queryable := qr.applyOptions(LocksView.
 		Where(where).
-		With(joins...)
+		With(joins...),
+	)
 
-	rows, err := qr.For(queryable)
+	rows, err := qr.Rows(queryable)
 	if err != nil {
 		return nil, errors.Wrap(err, "querying pg_locks")
 	}
@@ -130,7 +141,7 @@ func (qr QueryRunner) Locks9(where string, joins ...query.Queryable) ([]postgres
 	ls := make([]postgres9.LockJoined, 0)
 	for rows.Next() {
 		var l postgres9.LockJoined
-		dest := l.ScanDestinations(queryable.Joins)
+		dest := queryable.ScanDestinations(&l)
 
 		if err := rows.Scan(dest...); err != nil {
 			return nil, errors.Wrap(err, "scanning pg_locks row")
@@ -141,3 +152,103 @@ func (qr QueryRunner) Locks9(where string, joins ...query.Queryable) ([]postgres
 
 	return ls, nil
 }
+
+// StatStatements9 is a convenience method for running a query on pg_stat_statements view.
+// It is meant to be used for Postgres v9.6.
+// If you want to select rows with certain conditions, pass a non-empty where argument,
+// which will be injected as WHERE {where} in the query. Ordering, limits and
+// column projection can be set with QueryRunner.Options.
+// It fails with an error wrapping ErrStatStatementsNotInstalled if the extension
+// isn't created in the database.
+func (qr QueryRunner) StatStatements9(where string, joins ...query.Queryable) ([]postgres9.StatStatementJoined, error) {
+	if v := GetPostgresVersion(); v != Postgres9 {
+		return nil, getVersionMismatchError(Postgres9, v)
+	}
+
+	queryable := qr.applyOptions(StatStatementsView.
+		Where(where).
+		With(joins...),
+	)
+
+	rows, err := qr.Rows(queryable)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying pg_stat_statements")
+	}
+	defer rows.Close()
+
+	ss := make([]postgres9.StatStatementJoined, 0)
+	for rows.Next() {
+		var s postgres9.StatStatementJoined
+		dest := queryable.ScanDestinations(&s)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scanning pg_stat_statements row")
+		}
+
+		ss = append(ss, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading pg_stat_statements rows")
+	}
+
+	return ss, nil
+}
+
+// OldPreparedXacts9 returns the transactions prepared more than olderThan ago,
+// oldest first, along with the locks they hold in Locks and the locks waiting
+// for them in TxLocks. Forgotten prepared transactions keep holding their locks
+// and the xmin horizon until they're committed or rolled back with COMMIT
+// PREPARED or ROLLBACK PREPARED.
+// It is meant to be used for Postgres v9.6.
+func (qr QueryRunner) OldPreparedXacts9(olderThan time.Duration) ([]postgres9.PreparedXactJoined, error) {
+	if v := GetPostgresVersion(); v != Postgres9 {
+		return nil, getVersionMismatchError(Postgres9, v)
+	}
+
+	queryable := qr.applyOptions(PreparedXactsView.
+		Where(fmt.Sprintf("prepared < now() - interval '%s'", milliseconds(olderThan))).
+		OrderBy(Asc("prepared")).
+		With(
+			LocksView,
+			LocksOnTxIDView.Where("NOT pg_locks.granted"),
+		),
+	)
+
+	rows, err := qr.Rows(queryable)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying pg_prepared_xacts")
+	}
+	defer rows.Close()
+
+	ps := make([]postgres9.PreparedXactJoined, 0)
+	for rows.Next() {
+		var p postgres9.PreparedXactJoined
+		dest := queryable.ScanDestinations(&p)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scanning pg_prepared_xacts row")
+		}
+
+		ps = append(ps, p)
+	}
+
+	return ps, nil
+}
+
+// Snapshot9 reads the given views in a single query, so that their rows are
+// consistent with each other: the statistics views are all read from the same
+// snapshot of the statistics, taken at the first access in the transaction.
+// Each view can be given once, with its own Where, joins and options. The views
+// not given are left nil in the returned snapshot.
+// It is meant to be used for Postgres v9.6.
+func (qr QueryRunner) Snapshot9(views ...query.Queryable) (postgres9.Snapshot, error) {
+	if v := GetPostgresVersion(); v != Postgres9 {
+		return postgres9.Snapshot{}, getVersionMismatchError(Postgres9, v)
+	}
+
+	var s postgres9.Snapshot
+	if err := qr.snapshot(&s, views); err != nil {
+		return postgres9.Snapshot{}, err
+	}
+	return s, nil
+}
@@ -3,10 +3,23 @@ package pogo_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/sanggonlee/pogo"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+	"github.com/sanggonlee/pogo/postgres13"
+	"github.com/sanggonlee/pogo/postgres9"
+	"gopkg.in/guregu/null.v3"
 )
 
 func ExampleQuery() {
@@ -25,6 +38,157 @@ func ExampleQuery() {
 	}
 }
 
+func ExampleNewSession() {
+	var db *sql.DB
+	session := pogo.NewSession(db, pogo.SessionConfig{
+		StatementTimeout:                2 * time.Second,
+		LockTimeout:                     100 * time.Millisecond,
+		IdleInTransactionSessionTimeout: 10 * time.Second,
+		ApplicationName:                 "pogo",
+	})
+
+	statActivities, _ := pogo.QueryWith(session).StatActivity13("", pogo.LocksView)
+	for range statActivities {
+		// ...
+	}
+}
+
+func ExampleTrackXactStats13() {
+	var db *sql.DB
+	stats, _ := pogo.TrackXactStats13(context.Background(), db, func(tx *sql.Tx) error {
+		_, err := tx.Exec("UPDATE accounts SET balance = balance - 100 WHERE id = 1")
+		return err
+	})
+	for _, t := range stats.Tables {
+		if t.NumSequentialScans.Int64 > 0 {
+			// ...
+		}
+	}
+}
+
+func ExampleRegisterTarget() {
+	sessions, _ := pogo.RegisterTarget(pogo.TargetDefinition{
+		Relation:   "monitoring.sessions",
+		KeyColumns: []string{"pid"},
+	}, &session{}, &sessionJoined{})
+	_ = pogo.RegisterJoin(pogo.StatActivityView.Target, pogo.Join{
+		Target: sessions.Target,
+		Column: "sessions",
+		On:     []pogo.JoinOn{{Parent: "pid", Child: "pid"}},
+	})
+
+	var db pogo.Queryor
+	rows, _ := pogo.Query(db).For(
+		pogo.StatActivityView.With(sessions),
+	)
+	defer rows.Close()
+}
+
+func TestRegisterTarget(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+	t.Cleanup(query.SaveRegistry())
+
+	views, err := pogo.RegisterTarget(pogo.TargetDefinition{
+		Relation: "custom_views",
+		Joins: []pogo.Join{
+			{Target: pogo.LocksView.Target, Column: "locks", On: []pogo.JoinOn{{Parent: "pid", Child: "pid"}}},
+		},
+	}, &session{}, &sessionJoined{})
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	err = pogo.RegisterJoin(pogo.StatDatabaseView.Target, pogo.Join{
+		Target: views.Target,
+		Column: "custom_views",
+		On:     []pogo.JoinOn{{Parent: "datid", Child: "datid"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	q, err := pogo.StatDatabaseView.With(views.With(pogo.LocksView)).ToQuery()
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	for _, want := range []string{
+		"FROM custom_views AS custom_views_1",
+		"AS custom_views",
+		"custom_views_1.datid = pg_stat_database.datid",
+		"pg_locks_2.pid = custom_views_1.pid",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("Expected query to contain %q but got %s", want, q)
+		}
+	}
+
+	s, ok := views.NewScannable()
+	if _, isSession := s.(*sessionJoined); !ok || !isSession {
+		t.Errorf("Expected a new *sessionJoined but got %T", s)
+	}
+	if _, ok := pogo.StatActivityView.NewScannable(); ok {
+		t.Error("Expected no scannable registered for a built-in target")
+	}
+
+	scanner, err := pogo.NewStructScanner(views, sessionJoined{})
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	var ss []sessionJoined
+	rows := &valueRows{values: [][]interface{}{{int64(42), int64(7)}}}
+	if err := scanner.ScanAll(rows, &ss); err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	if len(ss) != 1 || ss[0].PID.Int64 != 42 || ss[0].DatID.Int64 != 7 {
+		t.Errorf("Expected the row to be scanned into the scannable but got %+v", ss)
+	}
+}
+
+func TestRegisterTarget_Validation(t *testing.T) {
+	t.Cleanup(query.SaveRegistry())
+
+	cases := []struct {
+		description string
+		def         pogo.TargetDefinition
+		scannable   pogo.Scannable
+	}{
+		{
+			description: "Definition without relation",
+			def:         pogo.TargetDefinition{},
+		},
+		{
+			description: "Join to an unregistered target",
+			def: pogo.TargetDefinition{Relation: "custom_views", Joins: []pogo.Join{
+				{Target: pogo.Target(9999), Column: "others", On: []pogo.JoinOn{{Parent: "pid", Child: "pid"}}},
+			}},
+		},
+		{
+			description: "Join to a relation without columns to join on",
+			def: pogo.TargetDefinition{Relation: "custom_views", Joins: []pogo.Join{
+				{Target: pogo.LocksView.Target, Column: "locks"},
+			}},
+		},
+		{
+			description: "Join to a function without arguments",
+			def: pogo.TargetDefinition{Relation: "custom_views", Joins: []pogo.Join{
+				{Target: pogo.BlockingPIDs.Target, Column: "blocked_by"},
+			}},
+		},
+		{
+			description: "Scannable not pointing to a struct",
+			def:         pogo.TargetDefinition{Relation: "custom_views"},
+			scannable:   sessionScannableFunc(nil),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := pogo.RegisterTarget(c.def, &session{}, c.scannable); err == nil {
+				t.Error("Expected error but got nil")
+			}
+		})
+	}
+}
+
 func TestQueryRunner_For(t *testing.T) {
 	cases := []struct {
 		description string
@@ -57,6 +221,921 @@ func TestQueryRunner_For(t *testing.T) {
 	}
 }
 
+func TestQueryWith(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	rq := &mockRowsQueryor{}
+	statActivities, err := pogo.QueryWith(rq).StatActivity13("state = 'active'")
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	if len(statActivities) != 0 {
+		t.Errorf("Expected no rows but got %d", len(statActivities))
+	}
+	if !strings.Contains(rq.query, "FROM pg_stat_activity AS pg_stat_activity") {
+		t.Errorf("Expected query on pg_stat_activity but got %s", rq.query)
+	}
+	if !rq.rows.closed {
+		t.Error("Expected rows to be closed")
+	}
+
+	if _, err := pogo.QueryWith(rq).For(pogo.LocksView); err == nil {
+		t.Error("Expected error for For without a database/sql queryor but got nil")
+	}
+}
+
+func TestQueryRunner_Hooks(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	hooks := &recordingHooks{}
+	rows, err := pogo.QueryWith(&mockRowsQueryor{}).Hooks(hooks).Rows(pogo.StatActivityView.With(pogo.LocksView.Count()))
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	if hooks.before == "" || hooks.after != "" {
+		t.Fatalf("Expected BeforeQuery only to be called before closing the rows")
+	}
+	for rows.Next() {
+	}
+	_ = rows.Close()
+	_ = rows.Close()
+
+	if hooks.after != hooks.before || hooks.calls != 2 {
+		t.Errorf("Expected AfterQuery to be called once with the query of BeforeQuery")
+	}
+	if hooks.description != "pg_stat_activity[pg_locks(count)]" {
+		t.Errorf("Expected queryable description in context but got %q", hooks.description)
+	}
+}
+
+func TestQueryRunner_Hooks_Args(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	hooks := &recordingHooks{}
+	rows, err := pogo.QueryWith(&valueRowsQueryor{rows: &valueRows{}}).Hooks(hooks).Rows(
+		pogo.StatActivityView.OrderBy(pogo.Asc("pid")).After(int64(42)),
+	)
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	_ = rows.Close()
+
+	if !reflect.DeepEqual(hooks.args, []interface{}{int64(42)}) {
+		t.Errorf("Expected the cursor value as argument but got %v", hooks.args)
+	}
+}
+
+func TestQueryRunner_Hooks_For(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	q := &contextQueryor{}
+	if _, err := pogo.Query(q).Hooks(&recordingHooks{}).For(pogo.StatActivityView); err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	if q.ctx == nil || q.ctx.Value(hooksKey{}) == nil {
+		t.Errorf("Expected the query to run with the context of the hooks")
+	}
+}
+
+func TestQueryRunner_Hooks_ScanError(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	scanErr := errors.New("cannot scan")
+	hooks := &recordingHooks{}
+	rows, err := pogo.QueryWith(&fixedRowsQueryor{&scanErrorRows{failAt: 2, err: scanErr}}).Hooks(hooks).Rows(pogo.StatActivityView)
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	for rows.Next() {
+		_ = rows.Scan()
+	}
+	_ = rows.Close()
+
+	if hooks.err != scanErr {
+		t.Errorf("Expected AfterQuery to get error %v but got %v", scanErr, hooks.err)
+	}
+	if hooks.rows != 2 {
+		t.Errorf("Expected AfterQuery to get the 2 rows scanned but got %d", hooks.rows)
+	}
+}
+
+type hooksKey struct{}
+
+type recordingHooks struct {
+	before, after, description string
+	args                       []interface{}
+	rows                       int
+	err                        error
+	calls                      int
+}
+
+func (h *recordingHooks) BeforeQuery(ctx context.Context, sql string, args []interface{}) context.Context {
+	h.before = sql
+	h.args = args
+	h.calls++
+	return context.WithValue(ctx, hooksKey{}, h)
+}
+
+func (h *recordingHooks) AfterQuery(ctx context.Context, sql string, duration time.Duration, rows int, err error) {
+	h.after = sql
+	h.description = pogo.QueryDescriptionFromContext(ctx)
+	h.rows = rows
+	h.err = err
+	h.calls++
+}
+
+// contextQueryor records the context of the queries run with QueryContext.
+type contextQueryor struct {
+	mockQueryor
+	ctx context.Context
+}
+
+func (q *contextQueryor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	q.ctx = ctx
+	return &sql.Rows{}, nil
+}
+
+type fixedRowsQueryor struct {
+	rows pogo.Rows
+}
+
+func (q *fixedRowsQueryor) QueryRows(ctx context.Context, query string, args ...interface{}) (pogo.Rows, error) {
+	return q.rows, nil
+}
+
+// scanErrorRows returns 3 rows, failing to scan the one at failAt.
+type scanErrorRows struct {
+	next   int
+	failAt int
+	err    error
+}
+
+func (r *scanErrorRows) Next() bool {
+	r.next++
+	return r.next <= 3
+}
+
+func (r *scanErrorRows) Scan(dest ...interface{}) error {
+	if r.next == r.failAt {
+		return r.err
+	}
+	return nil
+}
+
+func (r *scanErrorRows) Err() error   { return nil }
+func (r *scanErrorRows) Close() error { return nil }
+
+func TestQueryRunner_Prepare(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	compiled, err := pogo.StatActivityView.Columns("pid", "state").Compile()
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	rq := &mockRowsQueryor{}
+	stmt, err := pogo.QueryWith(rq).Prepare(compiled)
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < 2; i++ {
+		rq.query = ""
+		if _, err := stmt.Rows(); err != nil {
+			t.Fatalf("Expected nil error but got %v", err)
+		}
+		if rq.query != compiled.SQL() {
+			t.Errorf("Expected compiled query to run but got %s", rq.query)
+		}
+	}
+
+	var s postgres13.StatActivityJoined
+	if dests := stmt.ScanDestinations(&s); len(dests) != 2 || dests[0] != &s.PID || dests[1] != &s.State {
+		t.Errorf("Expected destinations of pid and state but got %v", dests)
+	}
+}
+
+type lockSummary struct {
+	Mode    string `pogo:"mode"`
+	Granted bool   `pogo:"granted"`
+}
+
+type sessionSummary struct {
+	PID       int64         `pogo:"pid"`
+	State     null.String   `pogo:"state"`
+	Locks     []lockSummary `pogo:"locks"`
+	NumLocks  int64         `pogo:"tx_locks_count"`
+	Untracked string
+}
+
+func TestStructScanner(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	queryable := pogo.StatActivityView.
+		Columns("pid", "state", "query").
+		With(pogo.LocksView, pogo.LocksOnTxIDView.Count())
+	scanner, err := pogo.NewStructScanner(queryable, []sessionSummary{})
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	rows := &valueRows{values: [][]interface{}{
+		{int64(42), "active", "SELECT 1", []byte(`[{"mode": "AccessShareLock", "granted": true}]`), int64(3)},
+		{int64(43), nil, "", "[]", int64(0)},
+	}}
+	var sessions []sessionSummary
+	if err := scanner.ScanAll(rows, &sessions); err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions but got %d", len(sessions))
+	}
+	first := sessions[0]
+	if first.PID != 42 || first.State.String != "active" || first.NumLocks != 3 {
+		t.Errorf("Expected columns to be scanned into fields but got %+v", first)
+	}
+	if len(first.Locks) != 1 || first.Locks[0] != (lockSummary{Mode: "AccessShareLock", Granted: true}) {
+		t.Errorf("Expected joined rows to be decoded into fields but got %+v", first.Locks)
+	}
+	if sessions[1].State.Valid || sessions[1].Locks == nil || len(sessions[1].Locks) != 0 {
+		t.Errorf("Expected null state and no locks but got %+v", sessions[1])
+	}
+}
+
+func TestNewStructScanner_Validation(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	cases := []struct {
+		description string
+		queryable   pogo.Queryable
+	}{
+		{
+			description: "Field mapped to a column that isn't selected should fail",
+			queryable:   pogo.StatActivityView.Columns("pid").With(pogo.LocksView, pogo.LocksOnTxIDView.Count()),
+		},
+		{
+			description: "Field of joined rows mapped to a column that isn't selected should fail",
+			queryable:   pogo.StatActivityView.With(pogo.LocksView.Columns("mode"), pogo.LocksOnTxIDView.Count()),
+		},
+		{
+			description: "Slice of structs mapped to a column not holding joined rows should fail",
+			queryable:   pogo.StatActivityView.With(pogo.LocksView.Count(), pogo.LocksOnTxIDView.Count()),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := pogo.NewStructScanner(c.queryable, sessionSummary{}); err == nil {
+				t.Error("Expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestStatActivityJoined_ScanDestinations(t *testing.T) {
+	conflicts := query.Queryable{Target: query.TargetStatDatabaseConflicts}
+	subscriptions := query.Queryable{Target: query.TargetStatSubscription}
+
+	var s13 postgres13.StatActivityJoined
+	dests := s13.ScanDestinations([]pogo.Queryable{conflicts, subscriptions.Count()})
+	if dests[len(dests)-2] != &s13.DatabaseConflicts || dests[len(dests)-1] != &s13.SubscriptionsAggregate.Count {
+		t.Errorf("Expected destinations of database conflicts and subscriptions count but got %v", dests[len(dests)-2:])
+	}
+
+	var s9 postgres9.StatActivityJoined
+	dests = s9.ScanDestinations([]pogo.Queryable{conflicts.Count()})
+	if dests[len(dests)-1] != &s9.DatabaseConflictsAggregate.Count {
+		t.Errorf("Expected destination of database conflicts count but got %v", dests[len(dests)-1])
+	}
+}
+
+func TestQueryRunner_ForMaps(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	rq := &valueRowsQueryor{rows: &valueRows{values: [][]interface{}{
+		{int64(42), []byte("active"), []byte(`[{"mode": "AccessShareLock", "pid": 42}]`), []byte("{7}")},
+	}}}
+	ms, err := pogo.QueryWith(rq).ForMaps(
+		pogo.StatActivityView.Columns("pid", "state").With(pogo.LocksView.Columns("mode", "pid"), pogo.BlockingPIDs),
+	)
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	expected := []map[string]interface{}{{
+		"pid":        int64(42),
+		"state":      "active",
+		"locks":      []interface{}{map[string]interface{}{"mode": "AccessShareLock", "pid": json.Number("42")}},
+		"blocked_by": "{7}",
+	}}
+	if !reflect.DeepEqual(ms, expected) {
+		t.Errorf("Expected maps %v but got %v", expected, ms)
+	}
+}
+
+func TestQueryRunner_Rows_After(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	rq := &valueRowsQueryor{rows: &valueRows{}}
+	rows, err := pogo.QueryWith(rq).Rows(
+		pogo.StatUserTablesView.OrderBy(pogo.Desc("n_dead_tup"), pogo.Asc("relid")).After(int64(100), int64(16384)),
+	)
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	defer rows.Close()
+
+	if !reflect.DeepEqual(rq.args, []interface{}{int64(100), int64(16384)}) {
+		t.Errorf("Expected the cursor values as arguments but got %v", rq.args)
+	}
+}
+
+func TestQueryRunner_StatStatements13_NotInstalled(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	// pgx reports the server errors once the rows are read, with their SQLSTATE.
+	missing := sqlStateError{state: "42P01"}
+	rq := &fixedRowsQueryor{&errRows{err: missing}}
+	_, err := pogo.QueryWith(rq).StatStatements13("calls > 100", pogo.StatDatabaseView)
+	if !errors.Is(err, pogo.ErrStatStatementsNotInstalled) {
+		t.Errorf("Expected error %v but got %v", pogo.ErrStatStatementsNotInstalled, err)
+	}
+	var stateErr sqlStateError
+	if !errors.As(err, &stateErr) {
+		t.Errorf("Expected the error of the driver to be wrapped but got %v", err)
+	}
+}
+
+func TestQueryRunner_StatStatementsMissing(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	// The message depends on lc_messages, so only the SQLSTATE is relied on.
+	missing := &pq.Error{Code: "42P01", Message: `relation "pg_stat_statements" n'existe pas`}
+	eq := &errorRowsQueryor{err: missing}
+	qr := pogo.QueryWith(eq)
+
+	if _, err := qr.Rows(pogo.StatStatementsView); !errors.Is(err, pogo.ErrStatStatementsNotInstalled) {
+		t.Errorf("Expected Rows error %v but got %v", pogo.ErrStatStatementsNotInstalled, err)
+	}
+	if _, err := qr.ForMaps(pogo.StatStatementsView); !errors.Is(err, pogo.ErrStatStatementsNotInstalled) {
+		t.Errorf("Expected ForMaps error %v but got %v", pogo.ErrStatStatementsNotInstalled, err)
+	}
+	if _, err := qr.Snapshot13(pogo.StatStatementsView); !errors.Is(err, pogo.ErrStatStatementsNotInstalled) {
+		t.Errorf("Expected Snapshot13 error %v but got %v", pogo.ErrStatStatementsNotInstalled, err)
+	}
+	var pqErr *pq.Error
+	if _, err := qr.StatStatements13(""); !errors.Is(err, pogo.ErrStatStatementsNotInstalled) || !errors.As(err, &pqErr) {
+		t.Errorf("Expected StatStatements13 error %v wrapping the error of the driver but got %v", pogo.ErrStatStatementsNotInstalled, err)
+	}
+
+	q := mockQueryor{queryError: missing}
+	if _, err := pogo.Query(q).For(pogo.StatStatementsView); !errors.Is(err, pogo.ErrStatStatementsNotInstalled) {
+		t.Errorf("Expected For error %v but got %v", pogo.ErrStatStatementsNotInstalled, err)
+	}
+
+	if _, err := qr.Rows(pogo.StatActivityView); errors.Is(err, pogo.ErrStatStatementsNotInstalled) {
+		t.Errorf("Expected missing relations of other queries to be left as is but got %v", err)
+	}
+	eq.err = &pq.Error{Code: "42501", Message: "permission denied for view pg_stat_statements"}
+	if _, err := qr.Rows(pogo.StatStatementsView); errors.Is(err, pogo.ErrStatStatementsNotInstalled) {
+		t.Errorf("Expected other errors to be left as is but got %v", err)
+	}
+}
+
+// sqlStateError is a server error reported the way pgx does.
+type sqlStateError struct {
+	state string
+}
+
+func (e sqlStateError) Error() string    { return "ERROR (SQLSTATE " + e.state + ")" }
+func (e sqlStateError) SQLState() string { return e.state }
+
+// errRows fails once read.
+type errRows struct {
+	err error
+}
+
+func (r *errRows) Next() bool                     { return false }
+func (r *errRows) Scan(dest ...interface{}) error { return nil }
+func (r *errRows) Err() error                     { return r.err }
+func (r *errRows) Close() error                   { return nil }
+
+func TestStatProgress(t *testing.T) {
+	bigint := func(i int64) pginternal.BigInt { return pginternal.BigInt{Int: null.IntFrom(i)} }
+	createIndex := postgres13.StatProgressCreateIndex{
+		Phase:       null.StringFrom("building index: scanning table"),
+		BlocksTotal: bigint(400),
+		BlocksDone:  bigint(100),
+	}
+
+	cases := []struct {
+		description string
+		percent     null.Float
+		expected    null.Float
+	}{
+		{
+			description: "Vacuum should report the heap blocks scanned",
+			percent: (&postgres13.StatProgressVacuum{
+				Phase:              null.StringFrom(string(postgres13.VacuumPhaseScanningHeap)),
+				HeapBlocksTotal:    bigint(200),
+				HeapBlocksScanned:  bigint(50),
+				HeapBlocksVacuumed: bigint(10),
+			}).PercentComplete(),
+			expected: null.FloatFrom(25),
+		},
+		{
+			description: "Vacuum should report the heap blocks vacuumed while vacuuming the heap",
+			percent: (&postgres13.StatProgressVacuum{
+				Phase:              null.StringFrom(string(postgres13.VacuumPhaseVacuumingHeap)),
+				HeapBlocksTotal:    bigint(200),
+				HeapBlocksScanned:  bigint(50),
+				HeapBlocksVacuumed: bigint(10),
+			}).PercentComplete(),
+			expected: null.FloatFrom(5),
+		},
+		{
+			description: "Index build should report the blocks done",
+			percent:     createIndex.PercentComplete(),
+			expected:    null.FloatFrom(25),
+		},
+		{
+			description: "Cluster should report nothing in phases without a total",
+			percent: (&postgres13.StatProgressCluster{
+				Phase:             null.StringFrom(string(postgres13.ClusterPhaseIndexScanningHeap)),
+				HeapTuplesScanned: bigint(1000),
+			}).PercentComplete(),
+		},
+		{
+			description: "Base backup should report nothing without an estimated size",
+			percent: (&postgres13.StatProgressBasebackup{
+				BackupStreamed: bigint(1 << 20),
+			}).PercentComplete(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if c.percent != c.expected {
+				t.Errorf("Expected %v but got %v", c.expected, c.percent)
+			}
+		})
+	}
+
+	if phase := createIndex.CurrentPhase(); phase != postgres13.CreateIndexPhaseBuildingIndex {
+		t.Errorf("Expected phase %q but got %q", postgres13.CreateIndexPhaseBuildingIndex, phase)
+	}
+}
+
+func TestQueryRunner_OldPreparedXacts13(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	rq := &mockRowsQueryor{}
+	if _, err := pogo.QueryWith(rq).OldPreparedXacts13(10 * time.Minute); err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	for _, expected := range []string{
+		"WHERE prepared < now() - interval '600000ms'",
+		"pg_locks_1.virtualtransaction = '-1/' || pg_prepared_xacts.transaction",
+		"pg_locks_2.transactionid = pg_prepared_xacts.transaction",
+		"ORDER BY pg_prepared_xacts.prepared ASC",
+	} {
+		if !strings.Contains(rq.query, expected) {
+			t.Errorf("Expected query to contain %q but got %s", expected, rq.query)
+		}
+	}
+}
+
+func TestQueryRunner_XminHolders(t *testing.T) {
+	rq := &valueRowsQueryor{rows: &valueRows{values: [][]interface{}{
+		{"prepared_xact", []byte("731"), int64(52000), nil, []byte("app"), nil, []byte("shop"), nil, nil, []byte("payment-17")},
+		{"slot_catalog_xmin", []byte("900"), int64(51831), nil, nil, nil, []byte("shop"), nil, []byte("cdc"), nil},
+	}}}
+	hs, err := pogo.QueryWith(rq).XminHolders()
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	expected := []pogo.XminHolder{
+		{
+			Kind:     pogo.XminHolderPreparedXact,
+			XID:      null.StringFrom("731"),
+			Age:      null.IntFrom(52000),
+			UserName: null.StringFrom("app"),
+			Database: null.StringFrom("shop"),
+			GID:      null.StringFrom("payment-17"),
+		},
+		{
+			Kind:     pogo.XminHolderSlotCatalogXMin,
+			XID:      null.StringFrom("900"),
+			Age:      null.IntFrom(51831),
+			Database: null.StringFrom("shop"),
+			SlotName: null.StringFrom("cdc"),
+		},
+	}
+	if !reflect.DeepEqual(hs, expected) {
+		t.Errorf("Expected holders %v but got %v", expected, hs)
+	}
+}
+
+func TestWraparoundAges(t *testing.T) {
+	ages := pogo.WraparoundAges{
+		XIDAge:                null.IntFrom(210000000),
+		MultiXactAge:          null.IntFrom(1000),
+		FreezeMaxAge:          null.IntFrom(200000000),
+		MultiXactFreezeMaxAge: null.IntFrom(400000000),
+	}
+
+	if n := ages.XIDsUntilAutovacuum(); n != null.IntFrom(-10000000) {
+		t.Errorf("Expected -10000000 XIDs until autovacuum but got %v", n)
+	}
+	if n := ages.XIDsUntilShutdown(); n != null.IntFrom(1936483647) {
+		t.Errorf("Expected 1936483647 XIDs until shutdown but got %v", n)
+	}
+	if n := ages.MultiXactsUntilAutovacuum(); n != null.IntFrom(399999000) {
+		t.Errorf("Expected 399999000 multixacts until autovacuum but got %v", n)
+	}
+	if !ages.NeedsAutovacuum() {
+		t.Error("Expected autovacuum to be needed past autovacuum_freeze_max_age")
+	}
+
+	// A null age makes the remaining IDs null rather than counting from 0.
+	unknown := pogo.WraparoundAges{
+		MultiXactAge:          null.IntFrom(1000),
+		FreezeMaxAge:          null.IntFrom(200000000),
+		MultiXactFreezeMaxAge: null.IntFrom(400000000),
+	}
+	if n := unknown.XIDsUntilAutovacuum(); n.Valid {
+		t.Errorf("Expected null XIDs until autovacuum but got %v", n)
+	}
+	if n := unknown.XIDsUntilShutdown(); n.Valid {
+		t.Errorf("Expected null XIDs until shutdown but got %v", n)
+	}
+	if unknown.NeedsAutovacuum() {
+		t.Error("Expected autovacuum not to be needed for unknown ages")
+	}
+}
+
+func TestTableAutovacuum(t *testing.T) {
+	var settings postgres13.AutovacuumSettings
+	for name, value := range map[string]string{
+		"autovacuum":                            "on",
+		"track_counts":                          "on",
+		"autovacuum_vacuum_threshold":           "50",
+		"autovacuum_vacuum_scale_factor":        "0.2",
+		"autovacuum_vacuum_insert_threshold":    "1000",
+		"autovacuum_vacuum_insert_scale_factor": "0.2",
+		"autovacuum_analyze_threshold":          "50",
+		"autovacuum_analyze_scale_factor":       "0.1",
+	} {
+		if err := settings.Set(name, value); err != nil {
+			t.Fatalf("Expected nil error but got %v", err)
+		}
+	}
+
+	tableSettings, err := settings.ForTable([]string{"autovacuum_vacuum_scale_factor=0.01", "autovacuum_enabled=false"})
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	if !settings.Enabled() || tableSettings.Enabled() {
+		t.Error("Expected autovacuum to be disabled only for the table")
+	}
+
+	table := postgres13.TableAutovacuum{
+		StatTable: postgres13.StatTable{
+			NumEstimatedDeadRows:        null.IntFrom(200),
+			NumInsertsSinceVacuum:       null.IntFrom(500),
+			NumRowsModifiedSinceAnalyze: null.IntFrom(700),
+		},
+		RelTuples: null.FloatFrom(10000),
+		Settings:  tableSettings,
+	}
+
+	if n := table.DeadRowsUntilVacuum(); n != -50 {
+		t.Errorf("Expected -50 dead rows until vacuum but got %d", n)
+	}
+	if n := table.InsertsUntilVacuum(); n != null.IntFrom(2500) {
+		t.Errorf("Expected 2500 inserts until vacuum but got %v", n)
+	}
+	if n := table.ModifiedRowsUntilAnalyze(); n != 350 {
+		t.Errorf("Expected 350 modified rows until analyze but got %d", n)
+	}
+	if !table.VacuumOverdue() || table.AnalyzeOverdue() {
+		t.Error("Expected the table to be overdue for vacuum only")
+	}
+
+	enabled := table
+	enabled.RelName = null.StringFrom("enabled")
+	enabled.Settings.TableDisabled = false
+	table.RelName = null.StringFrom("disabled")
+	health := postgres13.AutovacuumHealth{Settings: settings, Tables: []postgres13.TableAutovacuum{table, enabled}}
+	if overdue := health.OverdueTables(); len(overdue) != 1 || overdue[0].RelName.String != "enabled" {
+		t.Errorf("Expected only the enabled table to be overdue but got %+v", overdue)
+	}
+	if disabled := health.DisabledOverdueTables(); len(disabled) != 1 || disabled[0].RelName.String != "disabled" {
+		t.Errorf("Expected only the disabled table to be overdue with autovacuum disabled but got %+v", disabled)
+	}
+}
+
+func TestQueryRunner_AutovacuumHealth13(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	tableRow := make([]interface{}, 25)
+	tableRow[2] = []byte("accounts")
+	tableRow[12] = int64(200)
+	tableRow[23] = float64(10000)
+	tableRow[24] = []byte("{autovacuum_vacuum_scale_factor=0.01}")
+	rq := &queuedRowsQueryor{rows: []*valueRows{
+		{values: [][]interface{}{
+			{"autovacuum", "on"},
+			{"track_counts", "on"},
+			{"autovacuum_vacuum_threshold", "50"},
+			{"autovacuum_vacuum_scale_factor", "0.2"},
+		}},
+		{values: [][]interface{}{tableRow}},
+		{},
+	}}
+	health, err := pogo.QueryWith(rq).AutovacuumHealth13()
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+
+	if !strings.Contains(rq.queries[0], "name = ANY($1)") || len(rq.args[0]) != 1 {
+		t.Errorf("Expected the setting names to be bound as an array but got %s %v", rq.queries[0], rq.args[0])
+	}
+	reltuples := "(SELECT reltuples FROM pg_class WHERE pg_class.oid = pg_stat_user_tables.relid) AS reltuples"
+	if !strings.Contains(rq.queries[1], reltuples) || !strings.Contains(rq.queries[1], "ORDER BY pg_stat_user_tables.n_dead_tup DESC") {
+		t.Errorf("Expected pg_stat_user_tables with the columns of pg_class by most dead rows but got %s", rq.queries[1])
+	}
+
+	if len(health.Tables) != 1 {
+		t.Fatalf("Expected 1 table but got %+v", health.Tables)
+	}
+	table := health.Tables[0]
+	if table.RelName.String != "accounts" || table.Settings.VacuumScaleFactor != 0.01 {
+		t.Errorf("Expected accounts with its own scale factor but got %+v", table)
+	}
+	if n := table.DeadRowsUntilVacuum(); n != -50 {
+		t.Errorf("Expected -50 dead rows until vacuum but got %d", n)
+	}
+}
+
+// queuedRowsQueryor returns the rows in turn, recording the queries and their args.
+type queuedRowsQueryor struct {
+	rows    []*valueRows
+	queries []string
+	args    [][]interface{}
+}
+
+func (q *queuedRowsQueryor) QueryRows(ctx context.Context, query string, args ...interface{}) (pogo.Rows, error) {
+	q.queries = append(q.queries, query)
+	q.args = append(q.args, args)
+	rows := q.rows[0]
+	q.rows = q.rows[1:]
+	return rows, nil
+}
+
+func TestSession(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	d := &recordingDriver{}
+	sql.Register("pogo-session-test", d)
+	db, err := sql.Open("pogo-session-test", "")
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	defer db.Close()
+
+	session := pogo.NewSession(db)
+	for i := 0; i < 2; i++ {
+		if _, err := pogo.QueryWith(session).StatTable13(""); err != nil {
+			t.Fatalf("Expected nil error but got %v", err)
+		}
+	}
+
+	// Each query runs in a read-only transaction of its own, so that it reads
+	// fresh statistics, and the settings are local to it so that they don't
+	// outlive it on the pooled connection.
+	settings := "SELECT set_config('statement_timeout', $1, true), " +
+		"set_config('lock_timeout', $2, true), " +
+		"set_config('idle_in_transaction_session_timeout', $3, true), " +
+		"set_config('application_name', $4, true) [5000ms 1000ms 30000ms pogo]"
+	expected := []string{
+		"BEGIN READ ONLY",
+		settings,
+		"pg_stat_user_tables",
+		"ROLLBACK",
+		"BEGIN READ ONLY",
+		settings,
+		"pg_stat_user_tables",
+		"ROLLBACK",
+	}
+	if len(d.statements) != len(expected) {
+		t.Fatalf("Expected statements %v but got %v", expected, d.statements)
+	}
+	for i, e := range expected {
+		if !strings.Contains(d.statements[i], e) {
+			t.Errorf("Expected statement %d to contain %q but got %s", i, e, d.statements[i])
+		}
+	}
+}
+
+func TestTrackXactStats13(t *testing.T) {
+	_ = pogo.SetPostgresVersion(pogo.Postgres13)
+
+	d := &recordingDriver{row: []driver.Value{
+		time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		[]byte(`[{"relname": "accounts", "seq_scan": 1}]`),
+		[]byte(`[]`),
+	}}
+	sql.Register("pogo-xact-stats-test", d)
+	db, err := sql.Open("pogo-xact-stats-test", "")
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	defer db.Close()
+
+	stats, err := pogo.TrackXactStats13(context.Background(), db, func(tx *sql.Tx) error {
+		_, err := tx.Exec("UPDATE accounts SET balance = balance - 100 WHERE id = 1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected nil error but got %v", err)
+	}
+	if len(stats.Tables) != 1 || stats.Tables[0].RelName.String != "accounts" {
+		t.Errorf("Expected the statistics of accounts but got %+v", stats.Tables)
+	}
+
+	// The work of the function is discarded, whatever its outcome.
+	if last := d.statements[len(d.statements)-1]; last != "ROLLBACK" {
+		t.Errorf("Expected the transaction to be rolled back but got %v", d.statements)
+	}
+	for _, statement := range d.statements {
+		if statement == "COMMIT" {
+			t.Errorf("Expected the transaction not to be committed but got %v", d.statements)
+		}
+	}
+}
+
+// recordingDriver is a database/sql driver recording the statements run on
+// its connections. The queries return row if set, or no rows.
+type recordingDriver struct {
+	statements []string
+	row        []driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{d}, nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.ReadOnly {
+		c.record("BEGIN READ ONLY", nil)
+	} else {
+		c.record("BEGIN", nil)
+	}
+	return recordingTx{c}, nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query, args)
+	return &recordedRows{row: c.driver.row}, nil
+}
+
+func (c *recordingConn) record(query string, args []driver.NamedValue) {
+	if len(args) > 0 {
+		values := make([]string, 0, len(args))
+		for _, a := range args {
+			values = append(values, fmt.Sprint(a.Value))
+		}
+		query = fmt.Sprintf("%s [%s]", query, strings.Join(values, " "))
+	}
+	c.driver.statements = append(c.driver.statements, query)
+}
+
+type recordingTx struct {
+	conn *recordingConn
+}
+
+func (tx recordingTx) Commit() error {
+	tx.conn.record("COMMIT", nil)
+	return nil
+}
+
+func (tx recordingTx) Rollback() error {
+	tx.conn.record("ROLLBACK", nil)
+	return nil
+}
+
+type recordedRows struct {
+	row  []driver.Value
+	read bool
+}
+
+func (r *recordedRows) Columns() []string { return make([]string, len(r.row)) }
+func (r *recordedRows) Close() error      { return nil }
+
+func (r *recordedRows) Next(dest []driver.Value) error {
+	if r.row == nil || r.read {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.row)
+	return nil
+}
+
+type valueRowsQueryor struct {
+	rows *valueRows
+	args []interface{}
+}
+
+func (q *valueRowsQueryor) QueryRows(ctx context.Context, query string, args ...interface{}) (pogo.Rows, error) {
+	q.args = args
+	return q.rows, nil
+}
+
+// valueRows returns the given rows of driver values.
+type valueRows struct {
+	values [][]interface{}
+	next   int
+}
+
+func (r *valueRows) Next() bool {
+	r.next++
+	return r.next <= len(r.values)
+}
+
+func (r *valueRows) Scan(dest ...interface{}) error {
+	row := r.values[r.next-1]
+	for i, d := range dest {
+		if scanner, ok := d.(sql.Scanner); ok {
+			if err := scanner.Scan(row[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if row[i] != nil {
+			reflect.ValueOf(d).Elem().Set(reflect.ValueOf(row[i]))
+		}
+	}
+	return nil
+}
+
+func (r *valueRows) Err() error   { return nil }
+func (r *valueRows) Close() error { return nil }
+
+type errorRowsQueryor struct {
+	err error
+}
+
+func (q *errorRowsQueryor) QueryRows(ctx context.Context, query string, args ...interface{}) (pogo.Rows, error) {
+	return nil, q.err
+}
+
+type mockRowsQueryor struct {
+	query string
+	rows  *mockRows
+}
+
+func (mq *mockRowsQueryor) QueryRows(ctx context.Context, q string, args ...interface{}) (pogo.Rows, error) {
+	mq.query = q
+	mq.rows = &mockRows{}
+	return mq.rows, nil
+}
+
+type mockRows struct {
+	closed bool
+}
+
+func (mr *mockRows) Next() bool                     { return false }
+func (mr *mockRows) Scan(dest ...interface{}) error { return nil }
+func (mr *mockRows) Err() error                     { return nil }
+func (mr *mockRows) Close() error {
+	mr.closed = true
+	return nil
+}
+
 type mockQueryor struct {
 	queryError        error
 	queryContextError error
@@ -75,3 +1154,24 @@ func (mq mockQueryor) QueryContext(ctx context.Context, q string, args ...interf
 	}
 	return &sql.Rows{}, nil
 }
+
+type session struct{}
+
+func (s *session) Selects() []string {
+	return []string{"pid", "datid"}
+}
+
+type sessionJoined struct {
+	PID   null.Int
+	DatID null.Int
+}
+
+func (s *sessionJoined) ScanDestinations(joins []pogo.Queryable) []interface{} {
+	return []interface{}{&s.PID, &s.DatID}
+}
+
+type sessionScannableFunc func(joins []pogo.Queryable) []interface{}
+
+func (f sessionScannableFunc) ScanDestinations(joins []pogo.Queryable) []interface{} {
+	return f(joins)
+}
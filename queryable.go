@@ -28,11 +28,65 @@ var (
 	StatArchiverView          = query.Queryable{Target: query.TargetStatArchiver}
 	StatBGWriterView          = query.Queryable{Target: query.TargetStatBGWriter}
 	StatSLRUView              = query.Queryable{Target: query.TargetStatSLRU}
+
+	// StatStatementsView needs the pg_stat_statements extension.
+	StatStatementsView = query.Queryable{Target: query.TargetStatStatements}
+
+	StatProgressVacuumView      = query.Queryable{Target: query.TargetStatProgressVacuum}
+	StatProgressAnalyzeView     = query.Queryable{Target: query.TargetStatProgressAnalyze}
+	StatProgressCreateIndexView = query.Queryable{Target: query.TargetStatProgressCreateIndex}
+	StatProgressClusterView     = query.Queryable{Target: query.TargetStatProgressCluster}
+	StatProgressBasebackupView  = query.Queryable{Target: query.TargetStatProgressBasebackup}
+	ReplicationSlotsView        = query.Queryable{Target: query.TargetReplicationSlots}
+	PreparedXactsView           = query.Queryable{Target: query.TargetPreparedXacts}
+	StatXactUserTablesView      = query.Queryable{Target: query.TargetStatXactUserTables}
+	StatXactUserFunctionsView   = query.Queryable{Target: query.TargetStatXactUserFunctions}
 )
 
 // Non-relation queryables:
 var (
-	BlockingPIDs = query.Queryable{Target: query.TargetBlockingPIDs, SelectOnly: true}
+	BlockingPIDs             = query.Queryable{Target: query.TargetBlockingPIDs, SelectOnly: true}
+	SafeSnapshotBlockingPIDs = query.Queryable{Target: query.TargetSafeSnapshotBlockingPIDs, SelectOnly: true}
+	BackendXIDAge            = query.Queryable{Target: query.TargetBackendXIDAge, SelectOnly: true}
+	BackendXMinAge           = query.Queryable{Target: query.TargetBackendXMinAge, SelectOnly: true}
+	TotalRelationSize        = query.Queryable{Target: query.TargetTotalRelationSize, SelectOnly: true}
+	RelationSize             = query.Queryable{Target: query.TargetRelationSize, SelectOnly: true}
+	IndexesSize              = query.Queryable{Target: query.TargetIndexesSize, SelectOnly: true}
+	DatabaseSize             = query.Queryable{Target: query.TargetDatabaseSize, SelectOnly: true}
+	ReplayLagBytes           = query.Queryable{Target: query.TargetWALLSNDiff, SelectOnly: true}
+)
+
+// JoinStrategy determines the shape of the SQL generated for the joined queryables.
+type JoinStrategy = query.JoinStrategy
+
+// Join strategies, to be set with Queryable.JoinStrategy:
+const (
+	// JoinStrategyGroupBy left joins the children and groups the parent rows
+	// by all of their columns. This is the default.
+	JoinStrategyGroupBy = query.JoinStrategyGroupBy
+
+	// JoinStrategyLateral aggregates the children of each parent row in a
+	// correlated lateral subquery, without grouping the parent rows.
+	JoinStrategyLateral = query.JoinStrategyLateral
+)
+
+// Scope selects the objects covered by the table, index and sequence statistics
+// views.
+type Scope = query.Scope
+
+// Scopes, to be set with Queryable.Scope. They apply to the indexes and
+// sequences of the tables as well, e.g. StatUserIndexesView.Scope(pogo.SysTables)
+// reads pg_stat_sys_indexes:
+const (
+	// UserTables covers the user objects. This is the default.
+	UserTables = query.ScopeUser
+
+	// SysTables covers the objects in pg_catalog, information_schema and the
+	// toast schemas.
+	SysTables = query.ScopeSys
+
+	// AllTables covers both.
+	AllTables = query.ScopeAll
 )
 
 func setTargets(v version.PostgresVersion) {
@@ -41,6 +95,11 @@ func setTargets(v version.PostgresVersion) {
 		StatGSSAPIView.Target = query.TargetUnspecified
 		StatSubscriptionView.Target = query.TargetUnspecified
 		StatSLRUView.Target = query.TargetUnspecified
+		StatProgressAnalyzeView.Target = query.TargetUnspecified
+		StatProgressCreateIndexView.Target = query.TargetUnspecified
+		StatProgressClusterView.Target = query.TargetUnspecified
+		StatProgressBasebackupView.Target = query.TargetUnspecified
+		ReplayLagBytes.Target = query.TargetXLogLocationDiff
 	case version.Postgres13:
 	}
 }
@@ -67,6 +126,16 @@ func setSpecifiers(v version.PostgresVersion) {
 		StatArchiverView.Specifier = &postgres9.StatArchiver{}
 		StatBGWriterView.Specifier = &postgres9.StatBGWriter{}
 		StatSLRUView.Specifier = nil // Unsupported
+		StatStatementsView.Specifier = &postgres9.StatStatement{}
+		StatProgressVacuumView.Specifier = &postgres9.StatProgressVacuum{}
+		StatProgressAnalyzeView.Specifier = nil     // Unsupported
+		StatProgressCreateIndexView.Specifier = nil // Unsupported
+		StatProgressClusterView.Specifier = nil     // Unsupported
+		StatProgressBasebackupView.Specifier = nil  // Unsupported
+		ReplicationSlotsView.Specifier = &postgres9.ReplicationSlot{}
+		PreparedXactsView.Specifier = &postgres9.PreparedXact{}
+		StatXactUserTablesView.Specifier = &postgres9.StatXactTable{}
+		StatXactUserFunctionsView.Specifier = &postgres9.StatXactUserFunction{}
 	case version.Postgres13:
 		LocksView.Specifier = &postgres13.Lock{}
 		LocksOnTxIDView.Specifier = &postgres13.Lock{}
@@ -87,5 +156,15 @@ func setSpecifiers(v version.PostgresVersion) {
 		StatArchiverView.Specifier = &postgres13.StatArchiver{}
 		StatBGWriterView.Specifier = &postgres13.StatBGWriter{}
 		StatSLRUView.Specifier = &postgres13.StatSLRU{}
+		StatStatementsView.Specifier = &postgres13.StatStatement{}
+		StatProgressVacuumView.Specifier = &postgres13.StatProgressVacuum{}
+		StatProgressAnalyzeView.Specifier = &postgres13.StatProgressAnalyze{}
+		StatProgressCreateIndexView.Specifier = &postgres13.StatProgressCreateIndex{}
+		StatProgressClusterView.Specifier = &postgres13.StatProgressCluster{}
+		StatProgressBasebackupView.Specifier = &postgres13.StatProgressBasebackup{}
+		ReplicationSlotsView.Specifier = &postgres13.ReplicationSlot{}
+		PreparedXactsView.Specifier = &postgres13.PreparedXact{}
+		StatXactUserTablesView.Specifier = &postgres13.StatXactTable{}
+		StatXactUserFunctionsView.Specifier = &postgres13.StatXactUserFunction{}
 	}
 }
@@ -0,0 +1,56 @@
+package pogo
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo/internal/query"
+)
+
+// Queryable represents an abstract entity that you can run query against.
+type Queryable = query.Queryable
+
+// Target represents a single, non-recursive queryable target.
+type Target = query.Target
+
+// Selectable describes the columns selected from a target.
+type Selectable = query.Selectable
+
+// TargetDefinition declares a queryable target: the relation it reads from,
+// the columns identifying its rows, and the targets that can be joined under it.
+type TargetDefinition = query.TargetDefinition
+
+// Join declares how a target is joined under its parent target.
+type Join = query.Join
+
+// JoinOn is a pair of columns compared for equality in a join condition.
+type JoinOn = query.JoinOn
+
+// RegisterTarget registers a custom target, such as an in-house monitoring view
+// or a view provided by an extension, and returns a Queryable for it.
+// The specifier describes the columns selected from the target. The scannable,
+// if not nil, is a pointer to the struct its rows are scanned into, which
+// implements Scannable like the Joined structs of the built-in views. The
+// returned Queryable can be nested with With() like the built-in views, gives
+// new values of the scannable with NewScannable, and StructScanner scans its
+// rows into the scannable.
+func RegisterTarget(def TargetDefinition, specifier Selectable, scannable Scannable) (Queryable, error) {
+	if specifier == nil && !def.Function {
+		return Queryable{}, errors.Errorf("no specifier given for %s", def.Relation)
+	}
+
+	t, err := query.RegisterTarget(def, scannable)
+	if err != nil {
+		return Queryable{}, errors.Wrapf(err, "registering target %s", def.Relation)
+	}
+
+	return Queryable{
+		Target:     t,
+		Specifier:  specifier,
+		SelectOnly: def.Function,
+	}, nil
+}
+
+// RegisterJoin declares an additional join under an already registered target,
+// e.g. to join a custom target under one of the built-in views.
+func RegisterJoin(from Target, join Join) error {
+	return errors.Wrapf(query.RegisterJoin(from, join), "registering join under %s", from)
+}
@@ -0,0 +1,50 @@
+package pogo
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Rows is the result of a query, iterated with Next and read with Scan.
+// sql.Rows implements this, and drivers other than database/sql ones can be
+// adapted to it, such as pgx with the pgxadapter package.
+type Rows interface {
+	// Next prepares the next row for reading with Scan.
+	Next() bool
+
+	// Scan copies the columns of the current row into the destinations.
+	Scan(dest ...interface{}) error
+
+	// Err returns the error encountered during the iteration, if any.
+	Err() error
+
+	// Close closes the rows, preventing further iteration.
+	Close() error
+}
+
+// RowsQueryor is an interface for querying the database through a driver
+// other than database/sql. Run pogo queries with it with QueryWith.
+type RowsQueryor interface {
+	// QueryRows is used to query the database with a context.
+	QueryRows(ctx context.Context, query string, args ...interface{}) (Rows, error)
+}
+
+// sqlQueryor adapts a Queryor to RowsQueryor. A nil context runs the query
+// with Query rather than QueryContext.
+type sqlQueryor struct {
+	Queryor
+}
+
+func (q sqlQueryor) QueryRows(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	var rows *sql.Rows
+	var err error
+	if ctx == nil {
+		rows, err = q.Query(query, args...)
+	} else {
+		rows, err = q.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
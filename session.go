@@ -0,0 +1,144 @@
+package pogo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SessionConfig holds the settings of a monitoring session. A zero timeout
+// disables it, as in Postgres.
+type SessionConfig struct {
+	// StatementTimeout aborts the queries running longer than it.
+	StatementTimeout time.Duration
+
+	// LockTimeout aborts the queries waiting longer than it to acquire a lock.
+	LockTimeout time.Duration
+
+	// IdleInTransactionSessionTimeout terminates the connection if a query's
+	// transaction is left idle for longer than it, e.g. when its rows are
+	// read slowly or never closed.
+	IdleInTransactionSessionTimeout time.Duration
+
+	// ApplicationName is reported in pg_stat_activity.application_name for
+	// the session's queries. It's left unchanged if empty.
+	ApplicationName string
+
+	// IncludeOwnBackend keeps the rows of the session's own backend in the
+	// results, such as its row in pg_stat_activity and its locks in pg_locks.
+	IncludeOwnBackend bool
+}
+
+// DefaultSessionConfig is the configuration used by NewSession when none is given.
+var DefaultSessionConfig = SessionConfig{
+	StatementTimeout:                5 * time.Second,
+	LockTimeout:                     time.Second,
+	IdleInTransactionSessionTimeout: 30 * time.Second,
+	ApplicationName:                 "pogo",
+}
+
+// TxBeginner is an interface for beginning transactions.
+// sql.DB and sql.Conn implement this.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Session is a RowsQueryor running each query in a read-only transaction of
+// its own, with statement, lock and idle timeouts set locally to it, so that
+// monitoring never blocks or outlasts the production workload. The statistics
+// views are read afresh by every query, and the settings end with the
+// transaction, when the rows are closed. Run pogo queries with it with
+// QueryWith.
+type Session struct {
+	db                TxBeginner
+	settings          string
+	values            []interface{}
+	includeOwnBackend bool
+}
+
+// NewSession returns a monitoring session on db with the given configuration,
+// or DefaultSessionConfig if none is given.
+func NewSession(db TxBeginner, config ...SessionConfig) *Session {
+	c := DefaultSessionConfig
+	if len(config) > 0 {
+		c = config[0]
+	}
+
+	s := &Session{
+		db:                db,
+		includeOwnBackend: c.IncludeOwnBackend,
+	}
+	settings := []struct {
+		name, value string
+	}{
+		{"statement_timeout", milliseconds(c.StatementTimeout)},
+		{"lock_timeout", milliseconds(c.LockTimeout)},
+		{"idle_in_transaction_session_timeout", milliseconds(c.IdleInTransactionSessionTimeout)},
+	}
+	if c.ApplicationName != "" {
+		settings = append(settings, struct{ name, value string }{"application_name", c.ApplicationName})
+	}
+
+	// set_config with is_local is SET LOCAL taking its value as a parameter.
+	calls := make([]string, 0, len(settings))
+	for _, setting := range settings {
+		s.values = append(s.values, setting.value)
+		calls = append(calls, fmt.Sprintf("set_config('%s', $%d, true)", setting.name, len(s.values)))
+	}
+	s.settings = fmt.Sprintf("SELECT %s", strings.Join(calls, ", "))
+
+	return s
+}
+
+func milliseconds(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// QueryRows begins a read-only transaction with the session's settings, and
+// runs the query in it. Closing the rows ends the transaction.
+func (s *Session) QueryRows(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "beginning read-only transaction")
+	}
+	if _, err := tx.ExecContext(ctx, s.settings, s.values...); err != nil {
+		_ = tx.Rollback()
+		return nil, errors.Wrap(err, "setting session settings")
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return sessionRows{Rows: rows, tx: tx}, nil
+}
+
+// excludesOwnBackend reports whether the rows of the session's own backend
+// are left out of the results.
+func (s *Session) excludesOwnBackend() bool {
+	return !s.includeOwnBackend
+}
+
+// sessionRows are the rows of a session query, ending its transaction once
+// closed. The transaction only read, so it's rolled back.
+type sessionRows struct {
+	*sql.Rows
+	tx *sql.Tx
+}
+
+func (r sessionRows) Close() error {
+	err := r.Rows.Close()
+	if rollbackErr := r.tx.Rollback(); err == nil && rollbackErr != sql.ErrTxDone {
+		err = rollbackErr
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package pogo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo/internal/query"
+)
+
+// Compiled is a queryable converted to SQL once, returned by Queryable.Compile.
+type Compiled = query.Compiled
+
+// Preparer is an interface for preparing statements.
+// sql.DB, sql.Conn and sql.Tx implement this.
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// Statement is a compiled queryable bound to a runner, to be run repeatedly,
+// e.g. by a poller. When the runner's Queryor is a Preparer, the query is
+// prepared once. pgx prepares and caches the statements by itself.
+type Statement struct {
+	compiled Compiled
+	runner   QueryRunner
+	stmt     *sql.Stmt
+}
+
+// Prepare binds the compiled queryable to the runner, preparing it if possible.
+// Close the statement once done with it.
+func (qr QueryRunner) Prepare(compiled Compiled) (*Statement, error) {
+	if qr.excludesOwnBackend() {
+		var err error
+		if compiled, err = qr.excludeOwnBackend(compiled.Queryable()).Compile(); err != nil {
+			return nil, errors.Wrap(err, "compiling queryable")
+		}
+	}
+
+	s := &Statement{
+		compiled: compiled,
+		runner:   qr,
+	}
+
+	if p, ok := qr.queryor.(Preparer); ok {
+		ctx := qr.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		stmt, err := p.PrepareContext(ctx, compiled.SQL())
+		if err != nil {
+			return nil, errors.Wrap(err, "preparing statement")
+		}
+		s.stmt = stmt
+		s.runner.rowsQueryor = stmtQueryor{stmt}
+	}
+
+	return s, nil
+}
+
+// Rows runs the statement. Scan the rows with Statement.ScanDestinations.
+func (s *Statement) Rows() (Rows, error) {
+	return s.RowsContext(s.runner.ctx)
+}
+
+// RowsContext is like Rows, except it runs the statement with the context.
+func (s *Statement) RowsContext(ctx context.Context) (Rows, error) {
+	runner := s.runner
+	runner.ctx = ctx
+	rows, err := runner.query([]query.Queryable{s.compiled.Queryable()}, s.compiled.SQL(), s.compiled.Args()...)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying rows")
+	}
+	return rows, nil
+}
+
+// ScanDestinations returns the destinations of s for the columns of the statement.
+func (s *Statement) ScanDestinations(scannable Scannable) []interface{} {
+	return s.compiled.ScanDestinations(scannable)
+}
+
+// Close closes the prepared statement, if any.
+func (s *Statement) Close() error {
+	if s.stmt == nil {
+		return nil
+	}
+	return s.stmt.Close()
+}
+
+// stmtQueryor adapts a prepared statement to RowsQueryor, running the
+// statement whatever the query given.
+type stmtQueryor struct {
+	stmt *sql.Stmt
+}
+
+func (q stmtQueryor) QueryRows(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	var rows *sql.Rows
+	var err error
+	if ctx == nil {
+		rows, err = q.stmt.Query(args...)
+	} else {
+		rows, err = q.stmt.QueryContext(ctx, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,338 @@
+package pogo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo/internal/pginternal"
+	"github.com/sanggonlee/pogo/internal/query"
+)
+
+// Aggregate holds the rows of a joined queryable aggregated into a scalar
+// value, nested in the rows of its parent. See Queryable.Count.
+type Aggregate = pginternal.Aggregate
+
+// BigInt is the type of the bigint columns of the views, e.g. the counters
+// of pg_stat_database. It's null when the column is.
+type BigInt = pginternal.BigInt
+
+// Layout describes the columns of the rows of a queryable.
+type Layout = query.Layout
+
+// StructScanner scans the rows of a queryable into user defined structs,
+// mapping the columns to the fields tagged with `pogo:"column"`. The columns
+// of joined rows can be mapped to slices of such structs, e.g.
+//  type Session struct {
+//  	PID   int64       `pogo:"pid"`
+//  	State null.String `pogo:"state"`
+//  	Locks []struct {
+//  		Mode    string `pogo:"mode"`
+//  		Granted bool   `pogo:"granted"`
+//  	} `pogo:"locks"`
+//  }
+// The columns not mapped to any field are discarded. Structs without tags
+// implementing Scannable, such as the Joined structs or the scannable of a
+// custom target, are scanned into their ScanDestinations instead.
+type StructScanner struct {
+	queryable query.Queryable
+	layout    query.Layout
+	mapping   structMapping
+}
+
+// structMapping maps the columns of a layout to the fields of a struct type.
+type structMapping struct {
+	typ    reflect.Type
+	fields map[string]fieldMapping
+
+	// scannable is set for the types scanned into their ScanDestinations.
+	scannable bool
+}
+
+type fieldMapping struct {
+	index []int
+
+	// nested maps the columns of the joined rows to the fields of the slice
+	// elements, for the slices of tagged structs.
+	nested *structMapping
+}
+
+// NewStructScanner returns a scanner of the rows of the queryable into structs
+// of the type of v, which is a struct, a pointer to one, or a slice of either.
+// It checks that every tagged field, at any depth, is mapped to a column
+// selected by the queryable, so that mistakes are caught at startup.
+func NewStructScanner(queryable query.Queryable, v interface{}) (*StructScanner, error) {
+	layout, err := queryable.Layout()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting layout of queryable")
+	}
+
+	typ, ok := structType(reflect.TypeOf(v))
+	if !ok {
+		return nil, fmt.Errorf("%T is not a struct type", v)
+	}
+	if reflect.PtrTo(typ).Implements(scannableType) && !hasTags(typ) {
+		return &StructScanner{
+			queryable: queryable,
+			layout:    layout,
+			mapping:   structMapping{typ: typ, scannable: true},
+		}, nil
+	}
+	mapping, err := newStructMapping(typ, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StructScanner{
+		queryable: queryable,
+		layout:    layout,
+		mapping:   mapping,
+	}, nil
+}
+
+var scannableType = reflect.TypeOf((*Scannable)(nil)).Elem()
+
+// structType returns the struct type of t, dereferencing pointers and slices.
+func structType(t reflect.Type) (reflect.Type, bool) {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	return t, true
+}
+
+func newStructMapping(typ reflect.Type, layout query.Layout) (structMapping, error) {
+	m := structMapping{typ: typ, fields: make(map[string]fieldMapping)}
+	if err := m.add(typ, nil, layout); err != nil {
+		return structMapping{}, err
+	}
+	return m, nil
+}
+
+// add maps the tagged fields of typ, found at the index path, including the
+// ones of embedded structs.
+func (m structMapping) add(typ reflect.Type, index []int, layout query.Layout) error {
+	columns := make(map[string]bool, len(layout.Columns))
+	for _, c := range layout.Columns {
+		columns[c] = true
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		column := strings.Split(f.Tag.Get("pogo"), ",")[0]
+		if column == "-" {
+			continue
+		}
+		if column == "" {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				if err := m.add(f.Type, fieldIndex, layout); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if !columns[column] {
+			return fmt.Errorf("column %s of field %s.%s is not selected", column, typ.Name(), f.Name)
+		}
+		if _, ok := m.fields[column]; ok {
+			return fmt.Errorf("column %s is mapped to more than one field of %s", column, typ.Name())
+		}
+
+		fm := fieldMapping{index: fieldIndex}
+		if elem, ok := taggedElem(f.Type); ok {
+			joinLayout, ok := layout.Joins[column]
+			if !ok {
+				return fmt.Errorf("column %s of field %s.%s doesn't hold joined rows", column, typ.Name(), f.Name)
+			}
+			nested, err := newStructMapping(elem, joinLayout)
+			if err != nil {
+				return errors.Wrapf(err, "mapping rows of %s", column)
+			}
+			fm.nested = &nested
+		}
+		m.fields[column] = fm
+	}
+	return nil
+}
+
+// taggedElem returns the element type of the slices of structs with tagged
+// fields, which the joined rows are mapped into.
+func taggedElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Slice {
+		return nil, false
+	}
+	elem := t.Elem()
+	if elem.Kind() != reflect.Struct || !hasTags(elem) {
+		return nil, false
+	}
+	return elem, true
+}
+
+func hasTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup("pogo"); ok {
+			return true
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && hasTags(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// Layout returns the layout of the rows the scanner reads.
+func (s *StructScanner) Layout() query.Layout {
+	return s.layout
+}
+
+// Scan scans the current row into dest, a pointer to a struct of the scanner's type.
+func (s *StructScanner) Scan(rows Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Type() != s.mapping.typ {
+		return fmt.Errorf("%T is not a pointer to %s", dest, s.mapping.typ)
+	}
+	return s.scan(rows, v.Elem())
+}
+
+// ScanAll reads the remaining rows into dest, a pointer to a slice of structs
+// of the scanner's type, or of pointers to them.
+func (s *StructScanner) ScanAll(rows Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%T is not a pointer to a slice", dest)
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType != s.mapping.typ {
+		return fmt.Errorf("%T is not a pointer to a slice of %s", dest, s.mapping.typ)
+	}
+
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := s.scan(rows, elem.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+	return rows.Err()
+}
+
+func (s *StructScanner) scan(rows Rows, v reflect.Value) error {
+	if s.mapping.scannable {
+		dests := s.queryable.ScanDestinations(v.Addr().Interface().(Scannable))
+		return errors.Wrap(rows.Scan(dests...), "scanning row")
+	}
+
+	dests := make([]interface{}, len(s.layout.Columns))
+	var nested []func() error
+	for i, column := range s.layout.Columns {
+		fm, ok := s.mapping.fields[column]
+		if !ok {
+			dests[i] = new(interface{})
+			continue
+		}
+
+		field := v.FieldByIndex(fm.index)
+		if fm.nested == nil {
+			dests[i] = field.Addr().Interface()
+			continue
+		}
+
+		var raw []byte
+		dests[i] = &jsonColumn{&raw}
+		mapping, column := fm.nested, column
+		nested = append(nested, func() error {
+			return errors.Wrapf(mapping.decodeRows(raw, field), "decoding rows of %s", column)
+		})
+	}
+
+	if err := rows.Scan(dests...); err != nil {
+		return errors.Wrap(err, "scanning row")
+	}
+	for _, decode := range nested {
+		if err := decode(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonColumn scans a JSON column, which drivers give as bytes or as a string.
+type jsonColumn struct {
+	raw *[]byte
+}
+
+func (c *jsonColumn) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c.raw = nil
+	case []byte:
+		*c.raw = append([]byte{}, v...)
+	case string:
+		*c.raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported JSON value of type %T", value)
+	}
+	return nil
+}
+
+var _ sql.Scanner = &jsonColumn{}
+
+// decodeRows decodes a JSON array of joined rows into slice.
+func (m structMapping) decodeRows(raw []byte, slice reflect.Value) error {
+	if raw == nil {
+		return nil
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return err
+	}
+
+	decoded := reflect.MakeSlice(slice.Type(), 0, len(rows))
+	for _, row := range rows {
+		elem := reflect.New(m.typ).Elem()
+		if err := m.decodeRow(row, elem); err != nil {
+			return err
+		}
+		decoded = reflect.Append(decoded, elem)
+	}
+	slice.Set(decoded)
+	return nil
+}
+
+func (m structMapping) decodeRow(row map[string]json.RawMessage, v reflect.Value) error {
+	for column, fm := range m.fields {
+		raw, ok := row[column]
+		if !ok {
+			continue
+		}
+		field := v.FieldByIndex(fm.index)
+		if fm.nested != nil {
+			if err := fm.nested.decodeRows(raw, field); err != nil {
+				return errors.Wrapf(err, "decoding rows of %s", column)
+			}
+			continue
+		}
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			return errors.Wrapf(err, "decoding %s", column)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package pogo
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+)
+
+// The ages at which the server stops assigning transaction IDs and multixact
+// IDs to avoid wraparound, until the oldest ones are frozen by a vacuum in
+// single-user mode. They're 2^31 - 1 less the safety margins of Postgres v13
+// and before.
+const (
+	xidStopAge       = 1<<31 - 1 - 1000000
+	multiXactStopAge = 1<<31 - 1 - 100
+)
+
+// WraparoundAges are the ages of the oldest unfrozen transaction ID and
+// multixact ID of a database or a table, along with the ages at which
+// autovacuum is forced to freeze them.
+type WraparoundAges struct {
+	XIDAge       null.Int `json:"xid_age"`
+	MultiXactAge null.Int `json:"mxid_age"`
+
+	// FreezeMaxAge and MultiXactFreezeMaxAge are autovacuum_freeze_max_age and
+	// autovacuum_multixact_freeze_max_age, or the lower values set for the table.
+	FreezeMaxAge          null.Int `json:"freeze_max_age"`
+	MultiXactFreezeMaxAge null.Int `json:"multixact_freeze_max_age"`
+}
+
+// XIDsUntilAutovacuum returns the number of transactions left before autovacuum
+// is forced to freeze the oldest transaction ID, even if it's disabled.
+// It's negative once it's past due, and null if either age is.
+func (a WraparoundAges) XIDsUntilAutovacuum() null.Int {
+	return idsUntil(a.FreezeMaxAge, a.XIDAge)
+}
+
+// XIDsUntilShutdown returns the number of transactions left before the server
+// stops accepting commands that assign transaction IDs, or null if the age is.
+func (a WraparoundAges) XIDsUntilShutdown() null.Int {
+	return idsUntil(null.IntFrom(xidStopAge), a.XIDAge)
+}
+
+// MultiXactsUntilAutovacuum returns the number of multixacts left before
+// autovacuum is forced to freeze the oldest multixact ID.
+// It's negative once it's past due, and null if either age is.
+func (a WraparoundAges) MultiXactsUntilAutovacuum() null.Int {
+	return idsUntil(a.MultiXactFreezeMaxAge, a.MultiXactAge)
+}
+
+// MultiXactsUntilShutdown returns the number of multixacts left before the
+// server stops accepting commands that create multixacts, or null if the age is.
+func (a WraparoundAges) MultiXactsUntilShutdown() null.Int {
+	return idsUntil(null.IntFrom(multiXactStopAge), a.MultiXactAge)
+}
+
+// NeedsAutovacuum reports whether autovacuum should be freezing the database
+// or the table, i.e. any of its oldest IDs is past its freeze max age.
+// The IDs whose ages are unknown aren't counted.
+func (a WraparoundAges) NeedsAutovacuum() bool {
+	xids, multiXacts := a.XIDsUntilAutovacuum(), a.MultiXactsUntilAutovacuum()
+	return (xids.Valid && xids.Int64 < 0) || (multiXacts.Valid && multiXacts.Int64 < 0)
+}
+
+// idsUntil returns the number of IDs left before age reaches limit.
+func idsUntil(limit, age null.Int) null.Int {
+	if !limit.Valid || !age.Valid {
+		return null.Int{}
+	}
+	return null.IntFrom(limit.Int64 - age.Int64)
+}
+
+// DatabaseWraparound is the wraparound risk of a database, from pg_database.
+type DatabaseWraparound struct {
+	DatName null.String `json:"datname"`
+	WraparoundAges
+}
+
+// TableWraparound is the wraparound risk of a table, from pg_class, along with
+// its vacuum statistics from pg_stat_all_tables.
+type TableWraparound struct {
+	RelID      null.Int    `json:"relid"`
+	SchemaName null.String `json:"schemaname"`
+	RelName    null.String `json:"relname"`
+	WraparoundAges
+
+	LastVacuum      null.Time `json:"last_vacuum"`
+	LastAutovacuum  null.Time `json:"last_autovacuum"`
+	VacuumCount     null.Int  `json:"vacuum_count"`
+	AutovacuumCount null.Int  `json:"autovacuum_count"`
+}
+
+// WraparoundReport is the transaction ID wraparound risk of the databases of the
+// server and of the tables of the current database.
+type WraparoundReport struct {
+	Databases []DatabaseWraparound `json:"databases"`
+	Tables    []TableWraparound    `json:"tables"`
+}
+
+const databaseWraparoundQuery = `
+	SELECT
+		datname,
+		age(datfrozenxid),
+		mxid_age(datminmxid),
+		current_setting('autovacuum_freeze_max_age')::bigint,
+		current_setting('autovacuum_multixact_freeze_max_age')::bigint
+	FROM pg_database
+	ORDER BY age(datfrozenxid) DESC`
+
+// tableWraparoundQuery reads the system catalogs and TOAST tables as well as
+// the user tables, since they all have to be frozen. The freeze max ages set
+// for a table only apply when they're lower than the settings.
+const tableWraparoundQuery = `
+	SELECT
+		c.oid,
+		n.nspname,
+		c.relname,
+		age(c.relfrozenxid),
+		mxid_age(c.relminmxid),
+		LEAST(current_setting('autovacuum_freeze_max_age')::bigint, o.freeze_max_age),
+		LEAST(current_setting('autovacuum_multixact_freeze_max_age')::bigint, o.multixact_freeze_max_age),
+		s.last_vacuum,
+		s.last_autovacuum,
+		s.vacuum_count,
+		s.autovacuum_count
+	FROM pg_class AS c
+	JOIN pg_namespace AS n ON n.oid = c.relnamespace
+	LEFT JOIN pg_stat_all_tables AS s ON s.relid = c.oid
+	LEFT JOIN LATERAL (
+		SELECT
+			max(CASE WHEN option_name = 'autovacuum_freeze_max_age' THEN option_value::bigint END) AS freeze_max_age,
+			max(CASE WHEN option_name = 'autovacuum_multixact_freeze_max_age' THEN option_value::bigint END) AS multixact_freeze_max_age
+		FROM pg_options_to_table(c.reloptions)
+	) AS o ON true
+	WHERE c.relkind IN ('r', 'm', 't')
+	ORDER BY greatest(age(c.relfrozenxid), mxid_age(c.relminmxid)) DESC
+	%s`
+
+// Wraparound reports how close the databases and the tables of the current
+// database are to transaction ID and multixact ID wraparound, oldest first.
+// Only the limit oldest tables are returned, or all of them if limit is 0.
+// The remaining IDs before the forced autovacuum and the shutdown are given by
+// the WraparoundAges methods, and the tables autovacuum should be working on by
+// NeedsAutovacuum.
+// The views read are the same in Postgres v9.6 and v13.
+func (qr QueryRunner) Wraparound(limit int) (WraparoundReport, error) {
+	databases, err := qr.databaseWraparound()
+	if err != nil {
+		return WraparoundReport{}, err
+	}
+	tables, err := qr.tableWraparound(limit)
+	if err != nil {
+		return WraparoundReport{}, err
+	}
+	return WraparoundReport{Databases: databases, Tables: tables}, nil
+}
+
+func (qr QueryRunner) databaseWraparound() ([]DatabaseWraparound, error) {
+	rows, err := qr.query(nil, databaseWraparoundQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying pg_database")
+	}
+	defer rows.Close()
+
+	ds := make([]DatabaseWraparound, 0)
+	for rows.Next() {
+		var d DatabaseWraparound
+		if err := rows.Scan(
+			&d.DatName,
+			&d.XIDAge,
+			&d.MultiXactAge,
+			&d.FreezeMaxAge,
+			&d.MultiXactFreezeMaxAge,
+		); err != nil {
+			return nil, errors.Wrap(err, "scanning pg_database row")
+		}
+
+		ds = append(ds, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading pg_database rows")
+	}
+
+	return ds, nil
+}
+
+func (qr QueryRunner) tableWraparound(limit int) ([]TableWraparound, error) {
+	var limitClause string
+	if limit > 0 {
+		limitClause = fmt.Sprintf("LIMIT %d", limit)
+	}
+
+	rows, err := qr.query(nil, fmt.Sprintf(tableWraparoundQuery, limitClause))
+	if err != nil {
+		return nil, errors.Wrap(err, "querying pg_class")
+	}
+	defer rows.Close()
+
+	ts := make([]TableWraparound, 0)
+	for rows.Next() {
+		var t TableWraparound
+		if err := rows.Scan(
+			&t.RelID,
+			&t.SchemaName,
+			&t.RelName,
+			&t.XIDAge,
+			&t.MultiXactAge,
+			&t.FreezeMaxAge,
+			&t.MultiXactFreezeMaxAge,
+			&t.LastVacuum,
+			&t.LastAutovacuum,
+			&t.VacuumCount,
+			&t.AutovacuumCount,
+		); err != nil {
+			return nil, errors.Wrap(err, "scanning pg_class row")
+		}
+
+		ts = append(ts, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading pg_class rows")
+	}
+
+	return ts, nil
+}
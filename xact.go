@@ -0,0 +1,64 @@
+package pogo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/sanggonlee/pogo/internal/query"
+	"github.com/sanggonlee/pogo/postgres13"
+	"github.com/sanggonlee/pogo/postgres9"
+)
+
+// TrackXactStats13 runs fn in a transaction begun on db, and returns the table
+// and function statistics accumulated by the transaction, read from
+// pg_stat_xact_user_tables and pg_stat_xact_user_functions once fn returns.
+// The transaction is always rolled back afterwards, so the work of fn is
+// discarded. If fn fails, its error is returned as is. It's meant for
+// asserting on the work done by a code path in tests, e.g. that it did no
+// sequential scans.
+// It is meant to be used for Postgres v13.
+func TrackXactStats13(ctx context.Context, db TxBeginner, fn func(tx *sql.Tx) error) (postgres13.XactStats, error) {
+	if v := GetPostgresVersion(); v != Postgres13 {
+		return postgres13.XactStats{}, getVersionMismatchError(Postgres13, v)
+	}
+
+	var s postgres13.XactStats
+	if err := trackXactStats(ctx, db, fn, &s); err != nil {
+		return postgres13.XactStats{}, err
+	}
+	return s, nil
+}
+
+// TrackXactStats9 is like TrackXactStats13, except it is meant to be used for
+// Postgres v9.6.
+func TrackXactStats9(ctx context.Context, db TxBeginner, fn func(tx *sql.Tx) error) (postgres9.XactStats, error) {
+	if v := GetPostgresVersion(); v != Postgres9 {
+		return postgres9.XactStats{}, getVersionMismatchError(Postgres9, v)
+	}
+
+	var s postgres9.XactStats
+	if err := trackXactStats(ctx, db, fn, &s); err != nil {
+		return postgres9.XactStats{}, err
+	}
+	return s, nil
+}
+
+func trackXactStats(ctx context.Context, db TxBeginner, fn func(tx *sql.Tx) error, s query.Scannable) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "beginning transaction")
+	}
+
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	views := []query.Queryable{StatXactUserTablesView, StatXactUserFunctionsView}
+	if err := QueryContext(ctx, tx).snapshot(s, views); err != nil {
+		return errors.Wrap(err, "reading transaction statistics")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package pogo
+
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+)
+
+// XminHolderKind is the source of a transaction ID holding back the xmin horizon.
+type XminHolderKind string
+
+// Xmin horizon holder kinds
+const (
+	// XminHolderBackendXMin is the snapshot of a backend, e.g. of a long
+	// running query or a transaction left idle in a repeatable read transaction.
+	XminHolderBackendXMin XminHolderKind = "backend_xmin"
+
+	// XminHolderBackendXID is the transaction ID assigned to a backend, i.e. a
+	// transaction that has written something and is still open.
+	XminHolderBackendXID XminHolderKind = "backend_xid"
+
+	// XminHolderSlotXMin is the xmin of a replication slot, kept by a physical
+	// slot of a standby with hot_standby_feedback on.
+	XminHolderSlotXMin XminHolderKind = "slot_xmin"
+
+	// XminHolderSlotCatalogXMin is the catalog_xmin of a replication slot,
+	// kept by a logical slot for the catalog rows its decoding needs.
+	XminHolderSlotCatalogXMin XminHolderKind = "slot_catalog_xmin"
+
+	// XminHolderPreparedXact is a prepared transaction not yet committed or
+	// rolled back.
+	XminHolderPreparedXact XminHolderKind = "prepared_xact"
+
+	// XminHolderStandbyFeedback is the xmin a standby reports with
+	// hot_standby_feedback, for the queries running on it.
+	XminHolderStandbyFeedback XminHolderKind = "standby_feedback"
+)
+
+// XminHolder is a transaction ID holding back the xmin horizon, below which
+// vacuum can't remove dead rows.
+type XminHolder struct {
+	Kind XminHolderKind `json:"kind"`
+
+	// XID is the transaction ID held, and Age how many transactions old it is.
+	XID null.String `json:"xid"`
+	Age null.Int    `json:"age"`
+
+	// PID is the backend owning the transaction ID, if any: the walsender for
+	// standby feedback and the active slots, none for the prepared transactions
+	// and the inactive slots.
+	PID             null.Int    `json:"pid,omitempty"`
+	UserName        null.String `json:"usename,omitempty"`
+	ApplicationName null.String `json:"application_name,omitempty"`
+	Database        null.String `json:"database,omitempty"`
+	Query           null.String `json:"query,omitempty"`
+
+	// SlotName is set for the replication slots, and GID for the prepared
+	// transactions.
+	SlotName null.String `json:"slot_name,omitempty"`
+	GID      null.String `json:"gid,omitempty"`
+}
+
+// xminHoldersQuery collects the sources of old transaction IDs. The walsenders
+// and the backend running the query are left out of both backend branches: the
+// backend_xmin of the walsenders is reported as the standby feedback, and the
+// snapshot and transaction ID of the query's backend hold nothing back once
+// it's done, e.g. when XminHolders runs in a transaction that has written.
+const xminHoldersQuery = `
+	SELECT kind, xid, age(xid), pid, usename, application_name, database, query, slot_name, gid
+	FROM (
+		SELECT 'backend_xmin' AS kind, backend_xmin AS xid, pid, usename, application_name, datname AS database, query, NULL AS slot_name, NULL AS gid
+		FROM pg_stat_activity
+		WHERE backend_xmin IS NOT NULL
+			AND pid <> pg_backend_pid()
+			AND pid NOT IN (SELECT pid FROM pg_stat_replication)
+		UNION ALL
+		SELECT 'backend_xid', backend_xid, pid, usename, application_name, datname, query, NULL, NULL
+		FROM pg_stat_activity
+		WHERE backend_xid IS NOT NULL
+			AND pid <> pg_backend_pid()
+			AND pid NOT IN (SELECT pid FROM pg_stat_replication)
+		UNION ALL
+		SELECT 'slot_xmin', s.xmin, s.active_pid, a.usename, a.application_name, s.database, NULL, s.slot_name, NULL
+		FROM pg_replication_slots AS s
+		LEFT JOIN pg_stat_activity AS a ON a.pid = s.active_pid
+		WHERE s.xmin IS NOT NULL
+		UNION ALL
+		SELECT 'slot_catalog_xmin', s.catalog_xmin, s.active_pid, a.usename, a.application_name, s.database, NULL, s.slot_name, NULL
+		FROM pg_replication_slots AS s
+		LEFT JOIN pg_stat_activity AS a ON a.pid = s.active_pid
+		WHERE s.catalog_xmin IS NOT NULL
+		UNION ALL
+		SELECT 'prepared_xact', transaction, NULL, owner, NULL, database, NULL, NULL, gid
+		FROM pg_prepared_xacts
+		UNION ALL
+		SELECT 'standby_feedback', backend_xmin, pid, usename, application_name, NULL, NULL, NULL, NULL
+		FROM pg_stat_replication
+		WHERE backend_xmin IS NOT NULL
+	) AS holders
+	ORDER BY age(xid) DESC`
+
+// XminHolders returns everything holding back the xmin horizon, oldest first:
+// the snapshots and transaction IDs of the backends, the xmin and catalog_xmin
+// of the replication slots, the prepared transactions and the hot_standby_feedback
+// of the standbys. Vacuum can't remove the rows deleted after the oldest of them,
+// which is the first one returned.
+// The views read are the same in Postgres v9.6 and v13.
+func (qr QueryRunner) XminHolders() ([]XminHolder, error) {
+	rows, err := qr.query(nil, xminHoldersQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "querying xmin horizon holders")
+	}
+	defer rows.Close()
+
+	hs := make([]XminHolder, 0)
+	for rows.Next() {
+		var h XminHolder
+		var kind string
+		if err := rows.Scan(
+			&kind,
+			&h.XID,
+			&h.Age,
+			&h.PID,
+			&h.UserName,
+			&h.ApplicationName,
+			&h.Database,
+			&h.Query,
+			&h.SlotName,
+			&h.GID,
+		); err != nil {
+			return nil, errors.Wrap(err, "scanning xmin horizon holder row")
+		}
+		h.Kind = XminHolderKind(kind)
+
+		hs = append(hs, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading xmin horizon holder rows")
+	}
+
+	return hs, nil
+}